@@ -2,9 +2,14 @@
 // Interface generated from github.com/kmio11/codegen/_examples/interface.CalculatorInterface
 package main
 
+// CalculatorInterface is the interface of the methods of [Calculator].
+//
+// Calculator is a simple calculator implementation
 type CalculatorInterface interface {
 	Add(a int, b int) int
 	Divide(a int, b int) (int, error)
 	Multiply(a int, b int) int
 	Subtract(a int, b int) int
 }
+
+var _ CalculatorInterface = (*Calculator)(nil)
@@ -2,8 +2,13 @@
 // Interface generated from github.com/kmio11/codegen/_examples/interface.LoggerInterface
 package main
 
+// LoggerInterface is the interface of the methods of [Logger].
+//
+// Logger is a simple logging implementation
 type LoggerInterface interface {
 	Debug(message string)
 	Error(message string)
 	Info(message string)
 }
+
+var _ LoggerInterface = (*Logger)(nil)
@@ -2,6 +2,7 @@
 // Mock for github.com/kmio11/codegen/_examples/mock.Calculator
 package mock
 
+// MockCalculator is a test double for [Calculator].
 type MockCalculator struct {
 	Calculator
 	FakeAdd      func(a int, b int) int
@@ -10,18 +11,22 @@ type MockCalculator struct {
 	FakeSubtract func(a int, b int) int
 }
 
+// Add implements [Calculator.Add] by calling FakeAdd.
 func (m *MockCalculator) Add(a0 int, a1 int) int {
 	return m.FakeAdd(a0, a1)
 }
 
+// Divide implements [Calculator.Divide] by calling FakeDivide.
 func (m *MockCalculator) Divide(a0 int, a1 int) (int, error) {
 	return m.FakeDivide(a0, a1)
 }
 
+// Multiply implements [Calculator.Multiply] by calling FakeMultiply.
 func (m *MockCalculator) Multiply(a0 int, a1 int) int {
 	return m.FakeMultiply(a0, a1)
 }
 
+// Subtract implements [Calculator.Subtract] by calling FakeSubtract.
 func (m *MockCalculator) Subtract(a0 int, a1 int) int {
 	return m.FakeSubtract(a0, a1)
 }
@@ -2,6 +2,7 @@
 // Mock for github.com/kmio11/codegen/_examples/mock.Storage
 package mock
 
+// MockStorage is a test double for [Storage].
 type MockStorage[K comparable, V any] struct {
 	Storage[K, V]
 	FakeDelete func(key K)
@@ -10,18 +11,22 @@ type MockStorage[K comparable, V any] struct {
 	FakeSet    func(key K, value V)
 }
 
+// Delete implements [Storage.Delete] by calling FakeDelete.
 func (m *MockStorage[K, V]) Delete(a0 K) {
 	m.FakeDelete(a0)
 }
 
+// Get implements [Storage.Get] by calling FakeGet.
 func (m *MockStorage[K, V]) Get(a0 K) (V, bool) {
 	return m.FakeGet(a0)
 }
 
+// List implements [Storage.List] by calling FakeList.
 func (m *MockStorage[K, V]) List() []K {
 	return m.FakeList()
 }
 
+// Set implements [Storage.Set] by calling FakeSet.
 func (m *MockStorage[K, V]) Set(a0 K, a1 V) {
 	m.FakeSet(a0, a1)
 }
@@ -0,0 +1,373 @@
+package decorate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+var syncPkg = model.NewPkgInfo("sync", "sync", "")
+
+const (
+	cacheTTLName        = "TTL"
+	cacheMaxEntriesName = "MaxEntries"
+	cacheNowName        = "Now"
+	cacheMutexName      = "mu"
+	cacheEntriesName    = "cache"
+	cacheLoadName       = "load"
+	cacheStoreName      = "store"
+	cacheClockName      = "now"
+)
+
+func getCachedName(intfName string) string {
+	return "Cached" + intfName
+}
+
+// getCacheTypeName returns the name of the type used by the decorator, such as cachedStoreGetArgs.
+func getCacheTypeName(decoratorName, suffix string) string {
+	return strings.ToLower(decoratorName[:1]) + decoratorName[1:] + suffix
+}
+
+func getCacheArgsFieldName(i int) string {
+	return "A" + strconv.Itoa(i)
+}
+
+func getCacheResultFieldName(i int) string {
+	return "R" + strconv.Itoa(i)
+}
+
+// cachedMethod is the method whose results are memoized.
+type cachedMethod struct {
+	args    *model.Struct // the arguments other than context.Context, used as the key
+	results *model.Struct // the results other than error
+	argIdx  []int         // indexes of the arguments in args
+	resIdx  []int         // indexes of the results in results
+}
+
+// cacheDecorator adds the fields and the methods of the decorator memoizing the results of methods to d,
+// and returns the func setting the statements.
+// It fails if a method has no results or has parameters which are not comparable.
+func cacheDecorator(d *decorator, file *model.File, methods []string) (func(pm model.PackageMap), error) {
+	cached := map[string]*cachedMethod{}
+	for _, name := range methods {
+		m := findMethod(d.intf, name)
+		if m == nil {
+			return nil, fmt.Errorf("%s is not a method of %s", name, d.intf.Name())
+		}
+		if err := checkCacheable(d.intf, m, d.outPkg.Path(), *file.Dependencies()); err != nil {
+			return nil, err
+		}
+		cached[name] = &cachedMethod{
+			args:    cacheTupleStruct(d, getCacheTypeName(d.impl.Name(), m.Name()+"Args")),
+			results: cacheTupleStruct(d, getCacheTypeName(d.impl.Name(), m.Name()+"Results")),
+		}
+		sig := m.Type()
+		first := 0
+		if gen.HasContext(sig) {
+			first = 1
+		}
+		c := cached[name]
+		for i := first; i < len(sig.Args()); i++ {
+			c.args.AddField(model.NewField(getCacheArgsFieldName(i), sig.Args()[i].Type(), ""))
+			c.argIdx = append(c.argIdx, i)
+		}
+		errIndex := gen.ErrorResult(sig)
+		for i, r := range sig.Results() {
+			if i != errIndex {
+				c.results.AddField(model.NewField(getCacheResultFieldName(i), r.Type(), ""))
+				c.resIdx = append(c.resIdx, i)
+			}
+		}
+	}
+
+	durationType := model.NewTypeNamed(timePkg, "Duration", model.NewTypeBasic("int64"))
+	timeType := model.NewTypeNamed(timePkg, "Time", model.NewTypeStruct(nil))
+	entry := model.NewStruct(getCacheTypeName(d.impl.Name(), "Entry"), d.outPkg)
+	entry.AddField(model.NewField("value", gen.AnyType, ""))
+	entry.AddField(model.NewField("stored", timeType, ""))
+
+	d.addNext()
+	d.impl.AddField(model.NewField(cacheTTLName, durationType, ""))
+	d.impl.AddField(model.NewField(cacheMaxEntriesName, model.NewTypeBasic("int"), ""))
+	d.impl.AddField(model.NewField(cacheNowName, model.NewTypeSignature(nil, nil, []*model.Parameter{model.NewParameter("", timeType)}), ""))
+	d.impl.AddField(model.NewField(cacheMutexName, model.NewTypeNamed(syncPkg, "Mutex", model.NewTypeStruct(nil)), ""))
+	d.impl.AddField(model.NewField(cacheEntriesName, model.NewTypeMap(gen.AnyType, entry.Type()), ""))
+	d.impl.SetDoc(fmt.Sprintf(`%s wraps [%s] and memoizes the results of %s.
+The results are keyed by the arguments other than context.Context, and are not cached if the call fails.
+They are cached for TTL if it is positive, or until they are evicted. If MaxEntries is positive,
+the oldest result is evicted to cache another one when there are MaxEntries results.
+Now returns the current time for the TTL; it defaults to time.Now.
+The other methods are passed to Next as they are.`, d.impl.Name(), d.intfRef(), strings.Join(methods, ", ")))
+
+	d.addMethods(func(m *model.Func) string {
+		if cached[m.Name()] == nil {
+			return fmt.Sprintf("%s calls [%s.%s] of Next.", m.Name(), d.intfRef(), m.Name())
+		}
+		return fmt.Sprintf("%s calls [%s.%s] of Next, or returns the cached results of the same arguments.", m.Name(), d.intfRef(), m.Name())
+	})
+
+	// helpers shared by the methods
+	load := model.NewMethod(d.rcv, cacheLoadName, model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("key", gen.AnyType)},
+		nil,
+		[]*model.Parameter{model.NewParameter("", gen.AnyType), model.NewParameter("", model.NewTypeBasic("bool"))},
+	), "")
+	load.SetDoc(cacheLoadName + " returns the results cached for key unless they have expired.")
+	d.impl.AddMethod(load)
+	store := model.NewMethod(d.rcv, cacheStoreName, model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("key", gen.AnyType), model.NewParameter("value", gen.AnyType)},
+		nil, nil,
+	), "")
+	store.SetDoc(cacheStoreName + " caches the results for key, evicting the expired and the oldest results if there are MaxEntries results.")
+	d.impl.AddMethod(store)
+	now := model.NewMethod(d.rcv, cacheClockName, model.NewTypeSignature(nil, nil, []*model.Parameter{model.NewParameter("", timeType)}), "")
+	now.SetDoc(cacheClockName + " returns the current time.")
+	d.impl.AddMethod(now)
+
+	file.AddStruct(d.impl)
+	for _, m := range d.intf.Methods() {
+		if c := cached[m.Name()]; c != nil {
+			file.AddStruct(c.args)
+			file.AddStruct(c.results)
+		}
+	}
+	file.AddStruct(entry)
+	d.addConstructor(file,
+		[]*model.Parameter{
+			model.NewParameter("next", d.intfType),
+			model.NewParameter("ttl", durationType),
+		},
+		nil,
+		[]string{decoratorNextName, cacheTTLName},
+		fmt.Sprintf("New%s returns %s which caches the results of next for ttl.", d.impl.Name(), d.impl.Name()),
+	)
+
+	return func(pm model.PackageMap) {
+		setCacheStatements(d, cached, entry, load, store, now, pm)
+	}, nil
+}
+
+// cacheTupleStruct returns the struct holding the arguments or the results of a method.
+// It has the type parameters of the interface so that the fields can refer to them.
+func cacheTupleStruct(d *decorator, name string) *model.Struct {
+	if d.intf.IsGeneric() {
+		return model.NewGenericStruct(name, d.outPkg, d.intf.TypeParams())
+	}
+	return model.NewStruct(name, d.outPkg)
+}
+
+// findMethod returns the method of intf named name.
+func findMethod(intf *model.Interface, name string) *model.Func {
+	for _, m := range intf.Methods() {
+		if m.Name() == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// checkCacheable returns the error if the results of m cannot be memoized,
+// because it has no results or it has parameters which cannot be a part of the map key.
+func checkCacheable(intf *model.Interface, m *model.Func, myPkgPath string, pm model.PackageMap) error {
+	sig := m.Type()
+	if len(sig.Results()) == 0 {
+		return fmt.Errorf("%s.%s cannot be cached: it has no results", intf.Name(), m.Name())
+	}
+	if v := sig.Variadic(); v != nil {
+		return fmt.Errorf("%s.%s cannot be cached: parameter %s ...%s is not comparable",
+			intf.Name(), m.Name(), paramName(sig, len(sig.Args())), v.Type().PrintType(myPkgPath, pm))
+	}
+	for i, p := range sig.Args() {
+		if i == 0 && gen.HasContext(sig) {
+			continue
+		}
+		if !isComparable(p.Type()) {
+			return fmt.Errorf("%s.%s cannot be cached: parameter %s %s is not comparable",
+				intf.Name(), m.Name(), paramName(sig, i), p.Type().PrintType(myPkgPath, pm))
+		}
+	}
+	return nil
+}
+
+// isComparable returns true if the values of typ can be compared with == without panic.
+// Interfaces are rejected, since comparing them panics if their dynamic types are not comparable.
+func isComparable(typ model.Type) bool {
+	switch t := typ.(type) {
+	case *model.TypeArray:
+		return t.Len() >= 0 && isComparable(t.Type())
+	case *model.TypeMap, *model.TypeSignature, *model.TypeInterface:
+		return false
+	case *model.TypeStruct:
+		for _, f := range t.Fields() {
+			if !isComparable(f.Type()) {
+				return false
+			}
+		}
+		return true
+	case *model.TypeNamed:
+		if t.Org() == nil {
+			return true
+		}
+		return isComparable(t.Org())
+	case *model.TypeParameter:
+		return t.IsComparable()
+	}
+	// basic types, pointers and chans
+	return true
+}
+
+// setCacheStatements sets the statements of the methods of the decorator memoizing the results.
+func setCacheStatements(d *decorator, cached map[string]*cachedMethod, entry *model.Struct, load, store, now *model.Method, pm model.PackageMap) {
+	timeQ := gen.Qualifier(pm, timePkg, d.outPkg.Path())
+	w := decoratorRcvName
+
+	/*
+		key := cachedStoreGetArgs{A1: a1}
+		if v, ok := w.load(key); ok {
+			c := v.(cachedStoreGetResults)
+			return c.R0, nil
+		}
+		r0, r1 := w.Next.Get(a0, a1)
+		if r1 == nil {
+			w.store(key, cachedStoreGetResults{R0: r0})
+		}
+		return r0, r1
+	*/
+	methods := d.impl.Methods()
+	for i, m := range d.intf.Methods() {
+		sig := m.Type()
+		c := cached[m.Name()]
+		if c == nil {
+			s := callNext(m, callArgs(sig))
+			if len(sig.Results()) > 0 {
+				s = "return " + s
+			}
+			methods[i].SetStatements(s)
+			continue
+		}
+
+		errIndex := gen.ErrorResult(sig)
+		results := resultNames(sig)
+		tuple := func(s *model.Struct, idx []int, value func(i int) string) string {
+			fields := []string{}
+			for j, f := range s.Fields() {
+				fields = append(fields, f.Name()+": "+value(idx[j]))
+			}
+			return s.Type().PrintType(d.outPkg.Path(), pm) + "{" + strings.Join(fields, ", ") + "}"
+		}
+		cachedResults := []string{}
+		for j := range results {
+			if j == errIndex {
+				cachedResults = append(cachedResults, "nil")
+				continue
+			}
+			cachedResults = append(cachedResults, "c."+getCacheResultFieldName(j))
+		}
+
+		s := "key := " + tuple(c.args, c.argIdx, gen.ArgName) + "\n"
+		s += "if v, ok := " + w + "." + cacheLoadName + "(key); ok {\n"
+		s += "c := v.(" + c.results.Type().PrintType(d.outPkg.Path(), pm) + ")\n"
+		s += "return " + strings.Join(cachedResults, ", ") + "\n"
+		s += "}\n"
+		s += strings.Join(results, ", ") + " := " + callNext(m, callArgs(sig)) + "\n"
+		storeStmt := w + "." + cacheStoreName + "(key, " + tuple(c.results, c.resIdx, getResultName) + ")\n"
+		if errIndex >= 0 {
+			storeStmt = "if " + results[errIndex] + " == nil {\n" + storeStmt + "}\n"
+		}
+		s += storeStmt
+		s += "return " + strings.Join(results, ", ")
+		methods[i].SetStatements(s)
+	}
+
+	/*
+		now := w.now()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		e, ok := w.cache[key]
+		if !ok {
+			return nil, false
+		}
+		if w.TTL > 0 && now.Sub(e.stored) >= w.TTL {
+			delete(w.cache, key)
+			return nil, false
+		}
+		return e.value, true
+	*/
+	cache := w + "." + cacheEntriesName
+	expired := "if " + w + "." + cacheTTLName + " > 0 && now.Sub(e.stored) >= " + w + "." + cacheTTLName + " {\n"
+	lock := w + "." + cacheMutexName + ".Lock()\n" + "defer " + w + "." + cacheMutexName + ".Unlock()\n"
+
+	s := "now := " + w + "." + cacheClockName + "()\n"
+	s += lock
+	s += "e, ok := " + cache + "[key]\n"
+	s += "if !ok {\nreturn nil, false\n}\n"
+	s += expired
+	s += "delete(" + cache + ", key)\n"
+	s += "return nil, false\n"
+	s += "}\n"
+	s += "return e.value, true"
+	load.SetStatements(s)
+
+	/*
+		now := w.now()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.cache == nil {
+			w.cache = map[any]cachedStoreEntry{}
+		}
+		if _, ok := w.cache[key]; !ok && w.MaxEntries > 0 && len(w.cache) >= w.MaxEntries {
+			var oldest any
+			for k, e := range w.cache {
+				if w.TTL > 0 && now.Sub(e.stored) >= w.TTL {
+					delete(w.cache, k)
+					continue
+				}
+				if oldest == nil || e.stored.Before(w.cache[oldest].stored) {
+					oldest = k
+				}
+			}
+			if len(w.cache) >= w.MaxEntries {
+				delete(w.cache, oldest)
+			}
+		}
+		w.cache[key] = cachedStoreEntry{value: value, stored: now}
+	*/
+	maxEntries := w + "." + cacheMaxEntriesName
+	s = "now := " + w + "." + cacheClockName + "()\n"
+	s += lock
+	s += "if " + cache + " == nil {\n"
+	s += cache + " = map[any]" + entry.Name() + "{}\n"
+	s += "}\n"
+	s += "if _, ok := " + cache + "[key]; !ok && " + maxEntries + " > 0 && len(" + cache + ") >= " + maxEntries + " {\n"
+	s += "var oldest any\n"
+	s += "for k, e := range " + cache + " {\n"
+	s += expired
+	s += "delete(" + cache + ", k)\n"
+	s += "continue\n"
+	s += "}\n"
+	s += "if oldest == nil || e.stored.Before(" + cache + "[oldest].stored) {\n"
+	s += "oldest = k\n"
+	s += "}\n"
+	s += "}\n"
+	s += "if len(" + cache + ") >= " + maxEntries + " {\n"
+	s += "delete(" + cache + ", oldest)\n"
+	s += "}\n"
+	s += "}\n"
+	s += cache + "[key] = " + entry.Name() + "{value: value, stored: now}"
+	store.SetStatements(s)
+
+	/*
+		if w.Now != nil {
+			return w.Now()
+		}
+		return time.Now()
+	*/
+	s = "if " + w + "." + cacheNowName + " != nil {\n"
+	s += "return " + w + "." + cacheNowName + "()\n"
+	s += "}\n"
+	s += "return " + timeQ + "Now()"
+	now.SetStatements(s)
+}
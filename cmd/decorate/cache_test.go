@@ -0,0 +1,207 @@
+package decorate
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+	"github.com/kmio11/codegen/generator/parser"
+)
+
+func TestCacheDecorator(t *testing.T) {
+	pkg, intf := newTestInterface()
+	src := generate(t, pkg, intf, KindCache, model.NewPkgInfo("app", "example.com/app", ""), options{methods: []string{"Get"}})
+
+	expects := []string{
+		"type CachedUserService struct {",
+		"TTL        time.Duration",
+		"MaxEntries int",
+		"Now        func() time.Time",
+		"cache      map[any]cachedUserServiceEntry",
+		// the context is excluded from the key
+		"type cachedUserServiceGetArgs struct {\n\tA1 string\n}",
+		"type cachedUserServiceGetResults struct {\n\tR0 *User\n}",
+		"key := cachedUserServiceGetArgs{A1: a1}",
+		"c := v.(cachedUserServiceGetResults)\n\t\treturn c.R0, nil",
+		"r0, r1 := w.Next.Get(a0, a1)",
+		// failed calls are not cached
+		"if r1 == nil {\n\t\tw.store(key, cachedUserServiceGetResults{R0: r0})\n\t}",
+		// the methods not opted in are passed through
+		"func (w *CachedUserService) Notify(a0 ...string) {\n\tw.Next.Notify(a0...)\n}",
+		"return time.Now()",
+		"func NewCachedUserService(next UserService, ttl time.Duration) *CachedUserService {",
+		"return &CachedUserService{Next: next, TTL: ttl}",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(src, expect) {
+			t.Errorf("cacheDecorator() missing %q\n%s", expect, src)
+		}
+	}
+}
+
+func TestCacheDecoratorGeneric(t *testing.T) {
+	pkg, intf := newTestGenericInterface()
+	src := generate(t, pkg, intf, KindCache, model.NewPkgInfo("app", "example.com/app", ""), options{methods: []string{"Get"}})
+
+	expects := []string{
+		"type CachedCache[K comparable, V any] struct {",
+		"type cachedCacheGetArgs[K comparable, V any] struct {",
+		"key := cachedCacheGetArgs[K, V]{A0: a0}",
+		"c := v.(cachedCacheGetResults[K, V])\n\t\treturn c.R0, c.R1",
+		"w.store(key, cachedCacheGetResults[K, V]{R0: r0, R1: r1})",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(src, expect) {
+			t.Errorf("cacheDecorator() missing %q\n%s", expect, src)
+		}
+	}
+}
+
+func TestCacheDecoratorError(t *testing.T) {
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	pkg := &model.Package{
+		Name:         "app",
+		Path:         "example.com/app",
+		Dependencies: model.NewPackageMap("app", "example.com/app"),
+	}
+	intf := model.NewInterface("Search", appPkg, []*model.Func{
+		model.NewFunc("Find", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("tags", model.NewTypeArray(-1, model.NewTypeBasic("string")))},
+			nil,
+			[]*model.Parameter{model.NewParameter("", model.NewTypeBasic("int"))},
+		), ""),
+		model.NewFunc("Notify", model.NewTypeSignature(
+			nil,
+			model.NewParameter("msgs", model.NewTypeBasic("string")),
+			[]*model.Parameter{model.NewParameter("", model.NewTypeBasic("int"))},
+		), ""),
+		model.NewFunc("Reset", model.NewTypeSignature(nil, nil, nil), ""),
+		model.NewFunc("Lookup", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("v", gen.AnyType)},
+			nil,
+			[]*model.Parameter{model.NewParameter("", model.NewTypeBasic("int"))},
+		), ""),
+	})
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{"Find", "Search.Find cannot be cached: parameter tags []string is not comparable"},
+		{"Notify", "Search.Notify cannot be cached: parameter msgs ...string is not comparable"},
+		{"Reset", "Search.Reset cannot be cached: it has no results"},
+		{"Lookup", "Search.Lookup cannot be cached: parameter v any is not comparable"},
+		{"Get", "Get is not a method of Search"},
+	}
+	for _, tt := range tests {
+		_, err := decoratorFile(pkg, intf, KindCache, "", appPkg, options{methods: []string{tt.method}})
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("decoratorFile(%s) error = %v, want %v", tt.method, err, tt.want)
+		}
+	}
+}
+
+func TestCacheDecoratorNameConflict(t *testing.T) {
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	pkg := &model.Package{
+		Name:         "app",
+		Path:         "example.com/app",
+		Dependencies: model.NewPackageMap("app", "example.com/app"),
+	}
+	pkg.Dependencies.Add(timePkg.Path(), *timePkg)
+	timeType := model.NewTypeNamed(timePkg, "Time", model.NewTypeStruct(nil))
+	for _, name := range []string{cacheNowName, cacheTTLName, cacheMaxEntriesName} {
+		intf := model.NewInterface("Clock", appPkg, []*model.Func{
+			model.NewFunc(name, model.NewTypeSignature(nil, nil, []*model.Parameter{model.NewParameter("", timeType)}), ""),
+		})
+		_, err := decoratorFile(pkg, intf, KindCache, "", appPkg, options{methods: []string{name}})
+		want := "CachedClock cannot have both the field and the method named " + name
+		if err == nil || err.Error() != want {
+			t.Errorf("decoratorFile(%s) error = %v, want %v", name, err, want)
+		}
+	}
+}
+
+func TestCacheDecoratorParsed(t *testing.T) {
+	const src = `package app
+
+type Repo[K comparable, V any] interface {
+	Get(k K) (V, error)
+	Put(k K, v V) error
+}
+`
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "app.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typesPkg, err := (&types.Config{}).Check("example.com/app", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parse := func(typ string) (*model.Package, *model.Interface) {
+		p := parser.NewParser(
+			parser.OptPackage(&parser.Package{Name: "app", Files: []*ast.File{f}, Fset: fset, Pkg: typesPkg}),
+			parser.OptParseTarget([]string{typ}),
+		)
+		pkg, err := p.Parse()
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		return pkg, pkg.Interfaces[0]
+	}
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+
+	// the type parameter constrained by the predeclared comparable
+	pkg, intf := parse("Repo")
+	got := generate(t, pkg, intf, KindCache, appPkg, options{methods: []string{"Get"}})
+	if !strings.Contains(got, "key := cachedRepoGetArgs[K, V]{A0: a0}") {
+		t.Errorf("cacheDecorator() missing the key\n%s", got)
+	}
+
+	// the type parameter constrained by any
+	pkg, intf = parse("Repo")
+	_, err = decoratorFile(pkg, intf, KindCache, "", appPkg, options{methods: []string{"Put"}})
+	want := "Repo.Put cannot be cached: parameter v V is not comparable"
+	if err == nil || err.Error() != want {
+		t.Errorf("decoratorFile(Put) error = %v, want %v", err, want)
+	}
+}
+
+func TestIsComparable(t *testing.T) {
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	str := model.NewTypeBasic("string")
+	tests := []struct {
+		name string
+		typ  model.Type
+		want bool
+	}{
+		{"basic", str, true},
+		{"pointer", model.NewPointer(model.NewTypeArray(-1, str)), true},
+		{"array", model.NewTypeArray(2, str), true},
+		{"slice", model.NewTypeArray(-1, str), false},
+		{"map", model.NewTypeMap(str, str), false},
+		{"func", model.NewTypeSignature(nil, nil, nil), false},
+		{"struct", model.NewTypeStruct([]*model.Field{model.NewField("ID", str, "")}), true},
+		{"struct with slice", model.NewTypeStruct([]*model.Field{model.NewField("IDs", model.NewTypeArray(-1, str), "")}), false},
+		{"named", model.NewTypeNamed(appPkg, "Tags", model.NewTypeArray(-1, str)), false},
+		{"error", gen.ErrorType, false},
+		{"any", gen.AnyType, false},
+		{"pointer to interface", model.NewPointer(gen.AnyType), true},
+		{"struct with interface", model.NewTypeStruct([]*model.Field{model.NewField("V", gen.AnyType, "")}), false},
+		{"comparable type parameter", model.NewTypeParameter("K", model.ConstraintComparable, 0), true},
+		{"any type parameter", model.NewTypeParameter("V", model.ConstraintAny, 0), false},
+		{"predeclared comparable type parameter", model.NewTypeParameter("K", model.NewTypeNamed(nil, "comparable", model.NewTypeInterface(nil, nil)), 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isComparable(tt.typ); got != tt.want {
+				t.Errorf("isComparable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package decorate
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+// Kind is the kind of the decorator.
+type Kind string
+
+// Kinds.
+const (
+	KindLog   Kind = "log"   // log every call with log/slog
+	KindRetry Kind = "retry" // retry the methods returning error
+	KindCache Kind = "cache" // memoize the results of the methods
+	KindSync  Kind = "sync"  // serialize the calls with a mutex
+	KindMulti Kind = "multi" // forward the calls to all the members
+)
+
+// ParseKind returns Kind.
+func ParseKind(s string) (Kind, error) {
+	switch k := Kind(s); k {
+	case KindLog, KindRetry, KindCache, KindSync, KindMulti:
+		return k, nil
+	}
+	return "", fmt.Errorf("invalid kind %q: must be one of %s, %s, %s, %s, %s", s, KindLog, KindRetry, KindCache, KindSync, KindMulti)
+}
+
+// Command implements the decorator generation command
+type Command struct {
+	fs              *flag.FlagSet
+	flagPkg         *string
+	flagType        *string
+	flagKind        *string
+	flagOut         *string
+	flagOutPkg      *string
+	flagSelfPkgPath *string
+	flagMethods     *string
+	flagReadOnly    *string
+	flagFirst       *bool
+}
+
+// New creates a new decorate command
+func New() *Command {
+	c := &Command{}
+	c.fs = flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	c.flagPkg = c.fs.String("pkg", ".", "The package containing the interface to be decorated.")
+	c.flagType = c.fs.String("type", "", "The name of the interface to be decorated.")
+	c.flagKind = c.fs.String("kind", "", "The kind of the decorator: log, retry, cache, sync or multi.")
+	c.flagOut = c.fs.String("out", "", "Output file; defaults to stdout.")
+	c.flagOutPkg = c.fs.String("outpkg", "", "Output package name; defaults to the same package specified by -pkg")
+	c.flagSelfPkgPath = c.fs.String("selfpkg", "", "The full package import path of the output package.")
+	c.flagMethods = c.fs.String("methods", "", "Comma-separated methods to cache; required for -kind cache.")
+	c.flagReadOnly = c.fs.String("readonly", "", "Comma-separated read-only methods which hold the read lock of sync.RWMutex with -kind sync.")
+	c.flagFirst = c.fs.Bool("first", false, "Return the results other than error of the first member with -kind multi.")
+
+	return c
+}
+
+// Name returns the command name
+func (c Command) Name() string {
+	return "decorate"
+}
+
+// Description returns the command description
+func (c Command) Description() string {
+	return "generate decorator of interface"
+}
+
+// Usage prints usage information
+func (c Command) Usage(cmd string) {
+	fmt.Printf(`Usage:
+	%s %s -type <interface> -kind <kind> [flags]
+
+Generate a struct which implements the interface by wrapping another implementation of it.
+The kinds are:
+	log	log the method name, arguments, results, error and duration of every call with log/slog
+	retry	retry the methods returning error with exponential backoff, and time out each attempt
+	cache	memoize the results of the methods given by -methods, keyed by their arguments
+	sync	serialize every call with a mutex; the methods given by -readonly hold the read lock
+	multi	forward every call to all the members and join their errors; -first returns the results of the first member
+
+Examples:
+	# Generate LoggedUserService
+	%s %s -pkg . -type UserService -kind log -out user_service_log_gen.go
+
+Flags:
+`, cmd, c.Name(), cmd, c.Name())
+	c.fs.PrintDefaults()
+}
+
+// Parse parses command line arguments
+func (c *Command) Parse(args []string) error {
+	err := c.fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	// Validate required flags
+	if *c.flagType == "" {
+		return fmt.Errorf("-type flag is required")
+	}
+	kind, err := ParseKind(*c.flagKind)
+	if err != nil {
+		return fmt.Errorf("-kind: %w", err)
+	}
+	if kind == KindCache && *c.flagMethods == "" {
+		return fmt.Errorf("-methods flag is required for -kind %s", KindCache)
+	}
+	if kind != KindCache && *c.flagMethods != "" {
+		return fmt.Errorf("-methods can be used only with -kind %s", KindCache)
+	}
+	if kind != KindSync && *c.flagReadOnly != "" {
+		return fmt.Errorf("-readonly can be used only with -kind %s", KindSync)
+	}
+	if kind != KindMulti && *c.flagFirst {
+		return fmt.Errorf("-first can be used only with -kind %s", KindMulti)
+	}
+	if *c.flagOutPkg == "" && *c.flagSelfPkgPath != "" {
+		return fmt.Errorf("-selfpkg requires -outpkg")
+	}
+
+	return nil
+}
+
+// Execute runs the decorator generation command
+func (c *Command) Execute() int {
+	kind, err := ParseKind(*c.flagKind)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	// Parse the package
+	targetPkg, targetIntf, err := gen.ParseInterface(*c.flagType, *c.flagPkg)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	// the decorator cannot implement methods referring to unexported types of other packages.
+	outPkgName, outPkgPath := gen.OutPackage(targetPkg, *c.flagOut, *c.flagOutPkg, *c.flagSelfPkgPath)
+	targetIntf, err = generator.FilterUnexported(targetIntf, outPkgPath, generator.UnexportedError, log.Default())
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	// Create output file
+	opts := options{
+		methods:  splitNames(*c.flagMethods),
+		readOnly: splitNames(*c.flagReadOnly),
+		first:    *c.flagFirst,
+	}
+	file, err := decoratorFile(targetPkg, targetIntf, kind, *c.flagOut, model.NewPkgInfo(outPkgName, outPkgPath, ""), opts)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	// Generate code
+	g := &generator.Generator{}
+	src := g.
+		PrintHeader(c.Name()).
+		Printf("// Decorator for %s.%s", targetPkg.Path, targetIntf.Name()).
+		NewLine().
+		Printf("%s", file.PrintCode()).
+		Format()
+
+	// Output
+	if file.Path() == "" {
+		fmt.Println(string(src))
+	} else {
+		err := os.WriteFile(file.Path(), src, 0644)
+		if err != nil {
+			log.Printf("writing output: %s\n", err)
+			return 1
+		}
+		fmt.Printf("File created successfully : %s\n", file.Path())
+	}
+
+	return 0
+}
+
+// splitNames splits the comma-separated names.
+func splitNames(s string) []string {
+	names := []string{}
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// options controls the decorator of each kind.
+type options struct {
+	methods  []string // methods to cache
+	readOnly []string // methods holding the read lock
+	first    bool     // return the results of the first member
+}
+
+// decoratorFile returns the file which has the decorator of targetIntf.
+func decoratorFile(targetPkg *model.Package, targetIntf *model.Interface, kind Kind, outFile string, outPkg *model.PkgInfo, opts options) (*model.File, error) {
+	file := model.NewFile(outFile, outPkg.Name(), outPkg.Path(), targetPkg.CopyDependencies())
+	file.DependenciesTidy()
+
+	var d *decorator
+	var setStatements func(pm model.PackageMap)
+	switch kind {
+	case KindLog:
+		d = newDecorator(getLoggedName(targetIntf.Name()), targetIntf, outPkg)
+		setStatements = logDecorator(d, file)
+	case KindRetry:
+		d = newDecorator(getRetryingName(targetIntf.Name()), targetIntf, outPkg)
+		setStatements = retryDecorator(d, file)
+	case KindCache:
+		d = newDecorator(getCachedName(targetIntf.Name()), targetIntf, outPkg)
+		var err error
+		setStatements, err = cacheDecorator(d, file, opts.methods)
+		if err != nil {
+			return nil, err
+		}
+	case KindSync:
+		d = newDecorator(getSynchronizedName(targetIntf.Name()), targetIntf, outPkg)
+		var err error
+		setStatements, err = syncDecorator(d, file, opts.readOnly)
+		if err != nil {
+			return nil, err
+		}
+	case KindMulti:
+		d = newDecorator(getMultiName(targetIntf.Name()), targetIntf, outPkg)
+		var err error
+		setStatements, err = multiDecorator(d, file, opts.first)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid kind %q", kind)
+	}
+	if err := gen.CheckNames(d.impl); err != nil {
+		return nil, err
+	}
+	file.AddAssertion(model.NewAssertion(d.intfType, d.impl.Type(), true))
+
+	file.DependenciesTidy()
+
+	setStatements(*file.Dependencies())
+	return file, nil
+}
+
+const (
+	decoratorRcvName  = "w"
+	decoratorNextName = "Next"
+)
+
+var timePkg = model.NewPkgInfo("time", "time", "")
+
+// decorator is the struct implementing the interface by wrapping other implementations of it.
+type decorator struct {
+	intf     *model.Interface
+	intfType *model.TypeNamed // the interface referred from the output package
+	impl     *model.Struct
+	rcv      *model.Parameter
+	outPkg   *model.PkgInfo
+}
+
+// newDecorator returns the decorator named name.
+// Its fields and methods are added by each kind.
+func newDecorator(name string, intf *model.Interface, outPkg *model.PkgInfo) *decorator {
+	intfType := intf.Type()
+	impl := model.NewStruct(name, outPkg)
+	if intf.IsGeneric() {
+		intfType = model.NewGenericTypeNamed(intf.Type().Pkg(), intf.Name(), intf.Type().Org(), intf.TypeParams())
+		impl = model.NewGenericStruct(name, outPkg, intf.TypeParams())
+	}
+	return &decorator{
+		intf:     intf,
+		intfType: intfType,
+		impl:     impl,
+		rcv:      model.NewParameter(decoratorRcvName, model.NewPointer(impl.Type())),
+		outPkg:   outPkg,
+	}
+}
+
+// addNext adds the Next field holding the wrapped implementation.
+func (d *decorator) addNext() {
+	d.impl.AddField(model.NewField(decoratorNextName, d.intfType, ""))
+}
+
+// intfRef returns the doc link to the interface.
+func (d *decorator) intfRef() string {
+	return gen.IntfRef(d.intf, d.outPkg.Path())
+}
+
+// addConstructor adds New<Decorator> to file, which takes params and variadic, and returns the decorator.
+// fields[i] is the field initialized by params[i], followed by the one initialized by variadic.
+func (d *decorator) addConstructor(file *model.File, params []*model.Parameter, variadic *model.Parameter, fields []string, doc string) {
+	/*
+		return &LoggedXxx{Next: next, Logger: logger}
+	*/
+	inits := []string{}
+	for i, p := range params {
+		inits = append(inits, fields[i]+": "+p.Name())
+	}
+	if variadic != nil {
+		inits = append(inits, fields[len(params)]+": "+variadic.Name())
+	}
+	typ := d.impl.Name()
+	if d.impl.IsGeneric() {
+		names := []string{}
+		for _, tp := range d.impl.TypeParams() {
+			names = append(names, tp.Name())
+		}
+		typ += "[" + strings.Join(names, ", ") + "]"
+	}
+	sig := model.NewTypeSignature(params, variadic, []*model.Parameter{model.NewParameter("", model.NewPointer(d.impl.Type()))})
+	stmt := "return &" + typ + "{" + strings.Join(inits, ", ") + "}"
+
+	fn := model.NewFunc("New"+d.impl.Name(), sig, stmt)
+	if d.impl.IsGeneric() {
+		fn = model.NewGenericFunc("New"+d.impl.Name(), sig, stmt, d.impl.TypeParams())
+	}
+	fn.SetDoc(doc)
+	file.AddFunc(fn)
+}
+
+// addMethods adds the methods of the interface to the decorator.
+// The statements are set later by each kind.
+func (d *decorator) addMethods(doc func(m *model.Func) string) {
+	for _, m := range d.intf.Methods() {
+		method := model.NewMethod(d.rcv, m.Name(), gen.ArgNamedSignature(m.Type()), "")
+		method.SetDoc(doc(m))
+		d.impl.AddMethod(method)
+	}
+}
+
+// getResultName returns the name of the variable holding the i-th result.
+func getResultName(i int) string {
+	return "r" + strconv.Itoa(i)
+}
+
+// paramName returns the name of the i-th parameter in the interface, or a<i> if it is unnamed.
+func paramName(sig *model.TypeSignature, i int) string {
+	params := append([]*model.Parameter{}, sig.Args()...)
+	if sig.Variadic() != nil {
+		params = append(params, sig.Variadic())
+	}
+	if name := params[i].Name(); name != "" && name != "_" {
+		return name
+	}
+	return gen.ArgName(i)
+}
+
+// numParams returns the number of the parameters including the variadic one.
+func numParams(sig *model.TypeSignature) int {
+	if sig.Variadic() != nil {
+		return len(sig.Args()) + 1
+	}
+	return len(sig.Args())
+}
+
+// callArgs returns the arguments passing the parameters a0, a1, ... as they are.
+func callArgs(sig *model.TypeSignature) []string {
+	args := []string{}
+	for i := 0; i < numParams(sig); i++ {
+		args = append(args, gen.ArgName(i))
+	}
+	if sig.Variadic() != nil {
+		args[len(args)-1] += "..."
+	}
+	return args
+}
+
+// callNext returns the expression calling the method of Next with args.
+func callNext(m *model.Func, args []string) string {
+	return gen.CallMethod(decoratorRcvName+"."+decoratorNextName, m, args)
+}
+
+// resultNames returns the names of the variables holding the results.
+func resultNames(sig *model.TypeSignature) []string {
+	names := []string{}
+	for i := range sig.Results() {
+		names = append(names, getResultName(i))
+	}
+	return names
+}
@@ -0,0 +1,273 @@
+package decorate
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestCommand(t *testing.T) {
+	cmd := New()
+	if cmd == nil {
+		t.Fatal("New() returned nil")
+	}
+
+	if cmd.Name() != "decorate" {
+		t.Errorf("Name() = %v, want %v", cmd.Name(), "decorate")
+	}
+
+	if cmd.Description() != "generate decorator of interface" {
+		t.Errorf("Description() = %v, want %v", cmd.Description(), "generate decorator of interface")
+	}
+}
+
+func TestCommandParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		expectErr bool
+	}{
+		{
+			name:      "valid args",
+			args:      []string{"-pkg", ".", "-type", "UserService", "-kind", "log"},
+			expectErr: false,
+		},
+		{
+			name:      "retry",
+			args:      []string{"-type", "UserService", "-kind", "retry"},
+			expectErr: false,
+		},
+		{
+			name:      "cache",
+			args:      []string{"-type", "UserService", "-kind", "cache", "-methods", "Get,List"},
+			expectErr: false,
+		},
+		{
+			name:      "cache without methods",
+			args:      []string{"-type", "UserService", "-kind", "cache"},
+			expectErr: true,
+		},
+		{
+			name:      "methods without cache",
+			args:      []string{"-type", "UserService", "-kind", "log", "-methods", "Get"},
+			expectErr: true,
+		},
+		{
+			name:      "sync with readonly",
+			args:      []string{"-type", "UserService", "-kind", "sync", "-readonly", "Get"},
+			expectErr: false,
+		},
+		{
+			name:      "readonly without sync",
+			args:      []string{"-type", "UserService", "-kind", "cache", "-methods", "Get", "-readonly", "Get"},
+			expectErr: true,
+		},
+		{
+			name:      "multi with first",
+			args:      []string{"-type", "UserService", "-kind", "multi", "-first"},
+			expectErr: false,
+		},
+		{
+			name:      "first without multi",
+			args:      []string{"-type", "UserService", "-kind", "log", "-first"},
+			expectErr: true,
+		},
+		{
+			name:      "missing type",
+			args:      []string{"-kind", "log"},
+			expectErr: true,
+		},
+		{
+			name:      "missing kind",
+			args:      []string{"-type", "UserService"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid kind",
+			args:      []string{"-type", "UserService", "-kind", "trace"},
+			expectErr: true,
+		},
+		{
+			name:      "selfpkg without outpkg",
+			args:      []string{"-type", "UserService", "-kind", "log", "-selfpkg", "example.com/app"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := New()
+			err := cmd.Parse(tt.args)
+
+			if tt.expectErr && err == nil {
+				t.Error("Parse() should return error for invalid args")
+			}
+
+			if !tt.expectErr && err != nil {
+				t.Errorf("Parse() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCommandExecute(t *testing.T) {
+	cmd := New()
+
+	// This should fail because no valid kind is specified
+	if code := cmd.Execute(); code == 0 {
+		t.Error("Execute() should return non-zero exit code when no valid kind is specified")
+	}
+}
+
+func TestSplitNames(t *testing.T) {
+	got := splitNames("Get, List ,,Count")
+	want := []string{"Get", "List", "Count"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("splitNames() = %v, want %v", got, want)
+	}
+}
+
+func TestSignatureHelpers(t *testing.T) {
+	ctxType := model.NewTypeNamed(gen.ContextPkg, "Context", model.NewTypeInterface(nil, nil))
+	tests := []struct {
+		name       string
+		sig        *model.TypeSignature
+		wantParams []string
+	}{
+		{
+			name: "context and error",
+			sig: model.NewTypeSignature(
+				[]*model.Parameter{model.NewParameter("ctx", ctxType), model.NewParameter("", model.NewTypeBasic("string"))},
+				model.NewParameter("opts", model.NewTypeBasic("int")),
+				[]*model.Parameter{model.NewParameter("", model.NewTypeBasic("int")), model.NewParameter("", gen.ErrorType)},
+			),
+			wantParams: []string{"ctx", "a1", "opts"},
+		},
+		{
+			name:       "no results",
+			sig:        model.NewTypeSignature([]*model.Parameter{model.NewParameter("_", ctxType)}, nil, nil),
+			wantParams: []string{"a0"},
+		},
+		{
+			name: "error not last",
+			sig: model.NewTypeSignature(nil, nil,
+				[]*model.Parameter{model.NewParameter("", gen.ErrorType), model.NewParameter("", model.NewTypeBasic("int"))},
+			),
+			wantParams: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := numParams(tt.sig); got != len(tt.wantParams) {
+				t.Fatalf("numParams() = %v, want %v", got, len(tt.wantParams))
+			}
+			for i, want := range tt.wantParams {
+				if got := paramName(tt.sig, i); got != want {
+					t.Errorf("paramName(%d) = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// newTestInterface returns the interface
+//
+//	type UserService interface {
+//		Get(ctx context.Context, id string) (*User, error)
+//		Notify(msgs ...string)
+//	}
+func newTestInterface() (*model.Package, *model.Interface) {
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	pkg := &model.Package{
+		Name:         "app",
+		Path:         "example.com/app",
+		Dependencies: model.NewPackageMap("app", "example.com/app"),
+	}
+	pkg.Dependencies.Add(gen.ContextPkg.Path(), *gen.ContextPkg)
+
+	user := model.NewTypeNamed(appPkg, "User", model.NewTypeStruct(nil))
+	methods := []*model.Func{
+		model.NewFunc("Get", model.NewTypeSignature(
+			[]*model.Parameter{
+				model.NewParameter("ctx", model.NewTypeNamed(gen.ContextPkg, "Context", model.NewTypeInterface(nil, nil))),
+				model.NewParameter("id", model.NewTypeBasic("string")),
+			},
+			nil,
+			[]*model.Parameter{
+				model.NewParameter("", model.NewPointer(user)),
+				model.NewParameter("", gen.ErrorType),
+			},
+		), ""),
+		model.NewFunc("Notify", model.NewTypeSignature(
+			nil,
+			model.NewParameter("msgs", model.NewTypeBasic("string")),
+			nil,
+		), ""),
+	}
+	return pkg, model.NewInterface("UserService", appPkg, methods)
+}
+
+// newTestGenericInterface returns the interface
+//
+//	type Cache[K comparable, V any] interface {
+//		Get(key K) (V, bool)
+//	}
+func newTestGenericInterface() (*model.Package, *model.Interface) {
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	pkg := &model.Package{
+		Name:         "app",
+		Path:         "example.com/app",
+		Dependencies: model.NewPackageMap("app", "example.com/app"),
+	}
+	k := model.NewTypeParameter("K", model.NewTypeConstraint("comparable"), 0)
+	v := model.NewTypeParameter("V", model.ConstraintAny, 1)
+	methods := []*model.Func{
+		model.NewFunc("Get", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("key", k)},
+			nil,
+			[]*model.Parameter{model.NewParameter("", v), model.NewParameter("", model.NewTypeBasic("bool"))},
+		), ""),
+	}
+	return pkg, model.NewGenericInterface("Cache", appPkg, methods, []*model.TypeParameter{k, v})
+}
+
+// generate returns the formatted code of the decorator.
+func generate(t *testing.T, pkg *model.Package, intf *model.Interface, kind Kind, outPkg *model.PkgInfo, opts options) string {
+	t.Helper()
+	file, err := decoratorFile(pkg, intf, kind, "", outPkg, opts)
+	if err != nil {
+		t.Fatalf("decoratorFile() error = %v", err)
+	}
+	code := file.PrintCode()
+	src, err := format.Source([]byte(code))
+	if err != nil {
+		t.Fatalf("decoratorFile() generated invalid code: %v\n%s", err, code)
+	}
+	return string(src)
+}
+
+func TestDecoratorFileNameConflict(t *testing.T) {
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	pkg := &model.Package{
+		Name:         "app",
+		Path:         "example.com/app",
+		Dependencies: model.NewPackageMap("app", "example.com/app"),
+	}
+	// the method Next conflicts with the field holding the wrapped implementation.
+	intf := model.NewInterface("Rows", appPkg, []*model.Func{
+		model.NewFunc("Next", model.NewTypeSignature(nil, nil, []*model.Parameter{model.NewParameter("", model.NewTypeBasic("bool"))}), ""),
+		model.NewFunc("Err", model.NewTypeSignature(nil, nil, []*model.Parameter{model.NewParameter("", gen.ErrorType)}), ""),
+	})
+
+	for _, kind := range []Kind{KindLog, KindRetry, KindSync, KindMulti} {
+		t.Run(string(kind), func(t *testing.T) {
+			_, err := decoratorFile(pkg, intf, kind, "", appPkg, options{})
+			if err == nil || !strings.Contains(err.Error(), "Next") {
+				t.Errorf("decoratorFile() error = %v, want the conflict of Next", err)
+			}
+		})
+	}
+}
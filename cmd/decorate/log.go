@@ -0,0 +1,182 @@
+package decorate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+var slogPkg = model.NewPkgInfo("slog", "log/slog", "")
+
+const (
+	logLoggerName = "Logger"
+	logLevelName  = "Level"
+	logRedactName = "Redact"
+	logCallName   = "logCall"
+	logAttrName   = "logAttr"
+)
+
+func getLoggedName(intfName string) string {
+	return "Logged" + intfName
+}
+
+// logDecorator adds the fields and the methods of the decorator logging every call to d,
+// and returns the func setting the statements.
+func logDecorator(d *decorator, file *model.File) func(pm model.PackageMap) {
+	loggerType := model.NewPointer(model.NewTypeNamed(slogPkg, "Logger", model.NewTypeStruct(nil)))
+	levelType := model.NewTypeNamed(slogPkg, "Level", model.NewTypeBasic("int"))
+	attrsType := model.NewTypeArray(-1, model.NewTypeNamed(slogPkg, "Attr", model.NewTypeStruct(nil)))
+	stringType := model.NewTypeBasic("string")
+
+	d.addNext()
+	d.impl.AddField(model.NewField(logLoggerName, loggerType, ""))
+	d.impl.AddField(model.NewField(logLevelName, levelType, ""))
+	d.impl.AddField(model.NewField(logRedactName, model.NewTypeSignature(
+		[]*model.Parameter{
+			model.NewParameter("method", stringType),
+			model.NewParameter("key", stringType),
+			model.NewParameter("value", gen.AnyType),
+		},
+		nil,
+		[]*model.Parameter{model.NewParameter("", gen.AnyType)},
+	), ""))
+	d.impl.SetDoc(fmt.Sprintf(`%s wraps [%s] and logs the method name, arguments, results, error and duration of every call.
+Logger defaults to slog.Default(). Calls are logged at Level, or at slog.LevelError if they fail.
+Redact, if set, replaces the value of each logged argument and result, such as passwords and tokens.`, d.impl.Name(), d.intfRef()))
+
+	d.addMethods(func(m *model.Func) string {
+		return fmt.Sprintf("%s calls [%s.%s] of Next and logs the call.", m.Name(), d.intfRef(), m.Name())
+	})
+
+	// helpers shared by the methods
+	logCall := model.NewMethod(d.rcv, logCallName, model.NewTypeSignature(
+		[]*model.Parameter{
+			model.NewParameter("ctx", model.NewTypeNamed(gen.ContextPkg, "Context", model.NewTypeInterface(nil, nil))),
+			model.NewParameter("method", stringType),
+			model.NewParameter("start", model.NewTypeNamed(timePkg, "Time", model.NewTypeStruct(nil))),
+			model.NewParameter("err", gen.ErrorType),
+			model.NewParameter("args", attrsType),
+			model.NewParameter("results", attrsType),
+		},
+		nil, nil,
+	), "")
+	logCall.SetDoc(logCallName + " logs the call of the method.")
+	d.impl.AddMethod(logCall)
+	logAttr := model.NewMethod(d.rcv, logAttrName, model.NewTypeSignature(
+		[]*model.Parameter{
+			model.NewParameter("method", stringType),
+			model.NewParameter("key", stringType),
+			model.NewParameter("value", gen.AnyType),
+		},
+		nil,
+		[]*model.Parameter{model.NewParameter("", model.NewTypeNamed(slogPkg, "Attr", model.NewTypeStruct(nil)))},
+	), "")
+	logAttr.SetDoc(logAttrName + " returns the attribute of the logged value, replaced by Redact if set.")
+	d.impl.AddMethod(logAttr)
+
+	file.AddStruct(d.impl)
+	d.addConstructor(file,
+		[]*model.Parameter{
+			model.NewParameter("next", d.intfType),
+			model.NewParameter("logger", loggerType),
+		},
+		nil,
+		[]string{decoratorNextName, logLoggerName},
+		fmt.Sprintf("New%s returns %s which logs the calls to next with logger.", d.impl.Name(), d.impl.Name()),
+	)
+
+	return func(pm model.PackageMap) {
+		setLogStatements(d, logCall, logAttr, pm)
+	}
+}
+
+// setLogStatements sets the statements of the methods of the decorator logging every call.
+func setLogStatements(d *decorator, logCall, logAttr *model.Method, pm model.PackageMap) {
+	slogQ := gen.Qualifier(pm, slogPkg, d.outPkg.Path())
+	timeQ := gen.Qualifier(pm, timePkg, d.outPkg.Path())
+	contextQ := gen.Qualifier(pm, gen.ContextPkg, d.outPkg.Path())
+	w := decoratorRcvName
+
+	/*
+		start := time.Now()
+		r0, r1 := w.Next.Get(a0, a1)
+		w.logCall(a0, "Get", start, r1, []slog.Attr{w.logAttr("Get", "id", a1)}, []slog.Attr{w.logAttr("Get", "r0", r0)})
+		return r0, r1
+	*/
+	methods := d.impl.Methods()
+	for i, m := range d.intf.Methods() {
+		sig := m.Type()
+		ctx := contextQ + "Background()"
+		first := 0
+		if gen.HasContext(sig) {
+			ctx = gen.ArgName(0)
+			first = 1
+		}
+		args := []string{}
+		for j := first; j < numParams(sig); j++ {
+			args = append(args, fmt.Sprintf("%s.%s(%q, %q, %s)", w, logAttrName, m.Name(), paramName(sig, j), gen.ArgName(j)))
+		}
+		errIndex := gen.ErrorResult(sig)
+		err := "nil"
+		results := []string{}
+		for j, r := range resultNames(sig) {
+			if j == errIndex {
+				err = r
+				continue
+			}
+			results = append(results, fmt.Sprintf("%s.%s(%q, %q, %s)", w, logAttrName, m.Name(), r, r))
+		}
+		attrs := func(attrs []string) string {
+			if len(attrs) == 0 {
+				return "nil"
+			}
+			return "[]" + slogQ + "Attr{" + strings.Join(attrs, ", ") + "}"
+		}
+
+		s := "start := " + timeQ + "Now()\n"
+		if len(sig.Results()) > 0 {
+			s += strings.Join(resultNames(sig), ", ") + " := "
+		}
+		s += callNext(m, callArgs(sig)) + "\n"
+		s += fmt.Sprintf("%s.%s(%s, %q, start, %s, %s, %s)", w, logCallName, ctx, m.Name(), err, attrs(args), attrs(results))
+		if len(sig.Results()) > 0 {
+			s += "\nreturn " + strings.Join(resultNames(sig), ", ")
+		}
+		methods[i].SetStatements(s)
+	}
+
+	/*
+		logger := w.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		...
+		logger.LogAttrs(ctx, level, "call", attrs...)
+	*/
+	s := "logger := " + w + "." + logLoggerName + "\n"
+	s += "if logger == nil {\nlogger = " + slogQ + "Default()\n}\n"
+	s += "level := " + w + "." + logLevelName + "\n"
+	s += "attrs := []" + slogQ + "Attr{\n"
+	s += slogQ + "String(\"method\", " + fmt.Sprintf("%q", d.intf.Name()+".") + " + method),\n"
+	s += "{Key: \"args\", Value: " + slogQ + "GroupValue(args...)},\n"
+	s += "{Key: \"results\", Value: " + slogQ + "GroupValue(results...)},\n"
+	s += slogQ + "Duration(\"duration\", " + timeQ + "Since(start)),\n"
+	s += "}\n"
+	s += "if err != nil {\nlevel = " + slogQ + "LevelError\nattrs = append(attrs, " + slogQ + "Any(\"error\", err))\n}\n"
+	s += "logger.LogAttrs(ctx, level, \"call\", attrs...)"
+	logCall.SetStatements(s)
+
+	/*
+		if w.Redact != nil {
+			value = w.Redact(method, key, value)
+		}
+		return slog.Any(key, value)
+	*/
+	s = "if " + w + "." + logRedactName + " != nil {\n"
+	s += "value = " + w + "." + logRedactName + "(method, key, value)\n"
+	s += "}\n"
+	s += "return " + slogQ + "Any(key, value)"
+	logAttr.SetStatements(s)
+}
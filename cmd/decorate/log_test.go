@@ -0,0 +1,61 @@
+package decorate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestLogDecorator(t *testing.T) {
+	pkg, intf := newTestInterface()
+	src := generate(t, pkg, intf, KindLog, model.NewPkgInfo("app", "example.com/app", ""), options{})
+
+	expects := []string{
+		"type LoggedUserService struct {",
+		"Next   UserService",
+		"Logger *slog.Logger",
+		"Level  slog.Level",
+		"Redact func(method string, key string, value any) any",
+		"func (w *LoggedUserService) Get(a0 context.Context, a1 string) (*User, error) {",
+		"r0, r1 := w.Next.Get(a0, a1)",
+		// the context is not logged, but passed to the logger
+		`w.logCall(a0, "Get", start, r1, []slog.Attr{w.logAttr("Get", "id", a1)}, []slog.Attr{w.logAttr("Get", "r0", r0)})`,
+		"return r0, r1",
+		"w.Next.Notify(a0...)",
+		`w.logCall(context.Background(), "Notify", start, nil, []slog.Attr{w.logAttr("Notify", "msgs", a0)}, nil)`,
+		`slog.String("method", "UserService."+method)`,
+		`slog.Duration("duration", time.Since(start))`,
+		"value = w.Redact(method, key, value)",
+		"func NewLoggedUserService(next UserService, logger *slog.Logger) *LoggedUserService {",
+		"var _ UserService = (*LoggedUserService)(nil)",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(src, expect) {
+			t.Errorf("logDecorator() missing %q\n%s", expect, src)
+		}
+	}
+}
+
+func TestLogDecoratorGeneric(t *testing.T) {
+	pkg, intf := newTestGenericInterface()
+	src := generate(t, pkg, intf, KindLog, model.NewPkgInfo("logged", "example.com/app/logged", ""), options{})
+
+	expects := []string{
+		`"example.com/app"`,
+		"type LoggedCache[K comparable, V any] struct {",
+		"Next   app.Cache[K, V]",
+		"// Get calls [app.Cache.Get] of Next and logs the call.",
+		"func (w *LoggedCache[K, V]) Get(a0 K) (V, bool) {",
+		// results other than error are logged as results
+		`w.logCall(context.Background(), "Get", start, nil, []slog.Attr{w.logAttr("Get", "key", a0)}, []slog.Attr{w.logAttr("Get", "r0", r0), w.logAttr("Get", "r1", r1)})`,
+		"func NewLoggedCache[K comparable, V any](next app.Cache[K, V], logger *slog.Logger) *LoggedCache[K, V] {",
+		"return &LoggedCache[K, V]{Next: next, Logger: logger}",
+		"var _ app.Cache[K, V] = (*LoggedCache[K, V])(nil)",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(src, expect) {
+			t.Errorf("logDecorator() missing %q\n%s", expect, src)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package decorate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+var errorsPkg = model.NewPkgInfo("errors", "errors", "")
+
+const (
+	multiMembersName  = "Members"
+	multiParallelName = "Parallel"
+	multiFailFastName = "FailFast"
+	multiEachName     = "each"
+)
+
+func getMultiName(intfName string) string {
+	return "Multi" + intfName
+}
+
+// multiDecorator adds the fields and the methods of the composite forwarding every call to the members to d,
+// and returns the func setting the statements.
+// It fails if a method has results other than error, unless first is true and the results of the first member are returned.
+func multiDecorator(d *decorator, file *model.File, first bool) (func(pm model.PackageMap), error) {
+	if !first {
+		for _, m := range d.intf.Methods() {
+			if err := checkFanOut(d.intf, m); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	boolType := model.NewTypeBasic("bool")
+	d.impl.AddField(model.NewField(multiMembersName, model.NewTypeArray(-1, d.intfType), ""))
+	d.impl.AddField(model.NewField(multiParallelName, boolType, ""))
+	d.impl.AddField(model.NewField(multiFailFastName, boolType, ""))
+	doc := fmt.Sprintf(`%s implements [%s] by forwarding every call to all the Members, and joins their errors with errors.Join.
+The members are called one by one in order, or concurrently if Parallel is true.
+If FailFast is true, the first error is returned alone; the remaining members are not called if they are called one by one.`, d.impl.Name(), d.intfRef())
+	if first {
+		doc += "\nThe methods return the results other than error of the first member, or zero values if there are no members."
+	}
+	d.impl.SetDoc(doc)
+
+	d.addMethods(func(m *model.Func) string {
+		if first && len(m.Type().Results()) > 0 && gen.ErrorResult(m.Type()) != 0 {
+			return fmt.Sprintf("%s calls [%s.%s] of all the members, and returns the results of the first one.", m.Name(), d.intfRef(), m.Name())
+		}
+		return fmt.Sprintf("%s calls [%s.%s] of all the members.", m.Name(), d.intfRef(), m.Name())
+	})
+
+	// helpers shared by the methods
+	each := model.NewMethod(d.rcv, multiEachName, model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("call", model.NewTypeSignature(
+			[]*model.Parameter{
+				model.NewParameter("i", model.NewTypeBasic("int")),
+				model.NewParameter("m", d.intfType),
+			},
+			nil,
+			[]*model.Parameter{model.NewParameter("", gen.ErrorType)},
+		))},
+		nil,
+		[]*model.Parameter{model.NewParameter("", gen.ErrorType)},
+	), "")
+	each.SetDoc(multiEachName + " calls call with every member and its index, and returns the errors.")
+	d.impl.AddMethod(each)
+
+	file.AddImport(errorsPkg)
+	file.AddImport(syncPkg)
+	file.AddStruct(d.impl)
+	d.addConstructor(file,
+		nil,
+		model.NewParameter("members", d.intfType),
+		[]string{multiMembersName},
+		fmt.Sprintf("New%s returns %s which calls the members one by one.", d.impl.Name(), d.impl.Name()),
+	)
+
+	return func(pm model.PackageMap) {
+		setMultiStatements(d, each, pm)
+	}, nil
+}
+
+// checkFanOut returns the error if the results of m cannot be combined,
+// because it has results other than error.
+func checkFanOut(intf *model.Interface, m *model.Func) error {
+	sig := m.Type()
+	if n := len(sig.Results()); n > 1 || (n == 1 && gen.ErrorResult(sig) < 0) {
+		return fmt.Errorf("%s.%s cannot be fanned out: it has results other than error; use -first to return the results of the first member", intf.Name(), m.Name())
+	}
+	return nil
+}
+
+// setMultiStatements sets the statements of the methods of the composite forwarding every call to the members.
+func setMultiStatements(d *decorator, each *model.Method, pm model.PackageMap) {
+	errorsQ := gen.Qualifier(pm, errorsPkg, d.outPkg.Path())
+	syncQ := gen.Qualifier(pm, syncPkg, d.outPkg.Path())
+	intfType := d.intfType.PrintType(d.outPkg.Path(), pm)
+	w := decoratorRcvName
+
+	methods := d.impl.Methods()
+	for i, m := range d.intf.Methods() {
+		sig := m.Type()
+		errIndex := gen.ErrorResult(sig)
+		call := gen.CallMethod("m", m, callArgs(sig))
+		s := ""
+		switch {
+		case len(sig.Results()) == 0:
+			/*
+				w.each(func(i int, m Xxx) error {
+					m.Notify(a0...)
+					return nil
+				})
+			*/
+			s += w + "." + multiEachName + "(func(i int, m " + intfType + ") error {\n"
+			s += call + "\n"
+			s += "return nil\n"
+			s += "})"
+		case errIndex == 0:
+			/*
+				return w.each(func(i int, m Xxx) error {
+					return m.Put(a0, a1)
+				})
+			*/
+			s += "return " + w + "." + multiEachName + "(func(i int, m " + intfType + ") error {\n"
+			s += "return " + call + "\n"
+			s += "})"
+		default:
+			/*
+				var r0 *User
+				r1 := w.each(func(i int, m Xxx) error {
+					v0, err := m.Get(a0, a1)
+					if i == 0 {
+						r0 = v0
+					}
+					return err
+				})
+				return r0, r1
+			*/
+			values := []string{}
+			assigns := ""
+			for j, r := range sig.Results() {
+				if j == errIndex {
+					values = append(values, "err")
+					continue
+				}
+				v := "v" + strconv.Itoa(j)
+				values = append(values, v)
+				s += "var " + getResultName(j) + " " + r.Type().PrintType(d.outPkg.Path(), pm) + "\n"
+				assigns += getResultName(j) + " = " + v + "\n"
+			}
+			if errIndex >= 0 {
+				s += getResultName(errIndex) + " := "
+			}
+			s += w + "." + multiEachName + "(func(i int, m " + intfType + ") error {\n"
+			s += strings.Join(values, ", ") + " := " + call + "\n"
+			s += "if i == 0 {\n" + assigns + "}\n"
+			if errIndex >= 0 {
+				s += "return err\n"
+			} else {
+				s += "return nil\n"
+			}
+			s += "})\n"
+			s += "return " + strings.Join(resultNames(sig), ", ")
+		}
+		methods[i].SetStatements(s)
+	}
+
+	/*
+		if !w.Parallel {
+			var errs []error
+			for i, m := range w.Members {
+				if err := call(i, m); err != nil {
+					if w.FailFast {
+						return err
+					}
+					errs = append(errs, err)
+				}
+			}
+			return errors.Join(errs...)
+		}
+		errs := make([]error, len(w.Members))
+		var wg sync.WaitGroup
+		for i, m := range w.Members {
+			wg.Add(1)
+			go func(i int, m Xxx) {
+				defer wg.Done()
+				errs[i] = call(i, m)
+			}(i, m)
+		}
+		wg.Wait()
+		if w.FailFast {
+			for _, err := range errs {
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return errors.Join(errs...)
+	*/
+	members := w + "." + multiMembersName
+	s := "if !" + w + "." + multiParallelName + " {\n"
+	s += "var errs []error\n"
+	s += "for i, m := range " + members + " {\n"
+	s += "if err := call(i, m); err != nil {\n"
+	s += "if " + w + "." + multiFailFastName + " {\nreturn err\n}\n"
+	s += "errs = append(errs, err)\n"
+	s += "}\n"
+	s += "}\n"
+	s += "return " + errorsQ + "Join(errs...)\n"
+	s += "}\n"
+	s += "errs := make([]error, len(" + members + "))\n"
+	s += "var wg " + syncQ + "WaitGroup\n"
+	s += "for i, m := range " + members + " {\n"
+	s += "wg.Add(1)\n"
+	s += "go func(i int, m " + intfType + ") {\n"
+	s += "defer wg.Done()\n"
+	s += "errs[i] = call(i, m)\n"
+	s += "}(i, m)\n"
+	s += "}\n"
+	s += "wg.Wait()\n"
+	s += "if " + w + "." + multiFailFastName + " {\n"
+	s += "for _, err := range errs {\n"
+	s += "if err != nil {\nreturn err\n}\n"
+	s += "}\n"
+	s += "}\n"
+	s += "return " + errorsQ + "Join(errs...)"
+	each.SetStatements(s)
+}
@@ -0,0 +1,53 @@
+package decorate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestMultiDecorator(t *testing.T) {
+	pkg, intf := newTestInterface()
+	src := generate(t, pkg, intf, KindMulti, model.NewPkgInfo("app", "example.com/app", ""), options{first: true})
+
+	expects := []string{
+		"type MultiUserService struct {\n\tMembers  []UserService\n\tParallel bool\n\tFailFast bool\n}",
+		"func (w *MultiUserService) Get(a0 context.Context, a1 string) (*User, error) {\n\tvar r0 *User\n\tr1 := w.each(func(i int, m UserService) error {\n\t\tv0, err := m.Get(a0, a1)\n\t\tif i == 0 {\n\t\t\tr0 = v0\n\t\t}\n\t\treturn err\n\t})\n\treturn r0, r1\n}",
+		"func (w *MultiUserService) Notify(a0 ...string) {\n\tw.each(func(i int, m UserService) error {\n\t\tm.Notify(a0...)\n\t\treturn nil\n\t})\n}",
+		"func (w *MultiUserService) each(call func(i int, m UserService) error) error {",
+		"return errors.Join(errs...)",
+		"var wg sync.WaitGroup",
+		"func NewMultiUserService(members ...UserService) *MultiUserService {\n\treturn &MultiUserService{Members: members}\n}",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(src, expect) {
+			t.Errorf("multiDecorator() missing %q\n%s", expect, src)
+		}
+	}
+}
+
+func TestMultiDecoratorError(t *testing.T) {
+	pkg, intf := newTestInterface()
+	_, err := decoratorFile(pkg, intf, KindMulti, "", model.NewPkgInfo("app", "example.com/app", ""), options{})
+	want := "UserService.Get cannot be fanned out: it has results other than error; use -first to return the results of the first member"
+	if err == nil || err.Error() != want {
+		t.Errorf("decoratorFile() error = %v, want %v", err, want)
+	}
+}
+
+func TestMultiDecoratorGeneric(t *testing.T) {
+	pkg, intf := newTestGenericInterface()
+	src := generate(t, pkg, intf, KindMulti, model.NewPkgInfo("app", "example.com/app", ""), options{first: true})
+
+	expects := []string{
+		"type MultiCache[K comparable, V any] struct {\n\tMembers  []Cache[K, V]",
+		"func (w *MultiCache[K, V]) Get(a0 K) (V, bool) {\n\tvar r0 V\n\tvar r1 bool\n\tw.each(func(i int, m Cache[K, V]) error {\n\t\tv0, v1 := m.Get(a0)\n\t\tif i == 0 {\n\t\t\tr0 = v0\n\t\t\tr1 = v1\n\t\t}\n\t\treturn nil\n\t})\n\treturn r0, r1\n}",
+		"func NewMultiCache[K comparable, V any](members ...Cache[K, V]) *MultiCache[K, V] {",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(src, expect) {
+			t.Errorf("multiDecorator() missing %q\n%s", expect, src)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package decorate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+var randPkg = model.NewPkgInfo("rand", "math/rand", "")
+
+const (
+	retryMaxAttemptsName = "MaxAttempts"
+	retryBackoffName     = "Backoff"
+	retryMaxBackoffName  = "MaxBackoff"
+	retryJitterName      = "Jitter"
+	retryTimeoutName     = "Timeout"
+	retryRetryableName   = "Retryable"
+	retryOnAttemptName   = "OnAttempt"
+	retryName            = "retry"
+	retryDelayName       = "delay"
+	retryWithTimeoutName = "withTimeout"
+)
+
+func getRetryingName(intfName string) string {
+	return "Retrying" + intfName
+}
+
+// retryDecorator adds the fields and the methods of the decorator retrying the methods returning error to d,
+// and returns the func setting the statements.
+func retryDecorator(d *decorator, file *model.File) func(pm model.PackageMap) {
+	durationType := model.NewTypeNamed(timePkg, "Duration", model.NewTypeBasic("int64"))
+	contextType := model.NewTypeNamed(gen.ContextPkg, "Context", model.NewTypeInterface(nil, nil))
+	intType := model.NewTypeBasic("int")
+	stringType := model.NewTypeBasic("string")
+
+	d.addNext()
+	d.impl.AddField(model.NewField(retryMaxAttemptsName, intType, ""))
+	d.impl.AddField(model.NewField(retryBackoffName, durationType, ""))
+	d.impl.AddField(model.NewField(retryMaxBackoffName, durationType, ""))
+	d.impl.AddField(model.NewField(retryJitterName, model.NewTypeBasic("float64"), ""))
+	d.impl.AddField(model.NewField(retryTimeoutName, durationType, ""))
+	d.impl.AddField(model.NewField(retryRetryableName, model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("err", gen.ErrorType)},
+		nil,
+		[]*model.Parameter{model.NewParameter("", model.NewTypeBasic("bool"))},
+	), ""))
+	d.impl.AddField(model.NewField(retryOnAttemptName, model.NewTypeSignature(
+		[]*model.Parameter{
+			model.NewParameter("method", stringType),
+			model.NewParameter("attempt", intType),
+			model.NewParameter("err", gen.ErrorType),
+		},
+		nil, nil,
+	), ""))
+	d.impl.SetDoc(fmt.Sprintf(`%s wraps [%s] and retries the methods returning error.
+A method is called up to MaxAttempts times while it fails with an error for which Retryable returns true;
+every error is retried if Retryable is nil. The delay before the n-th retry is Backoff * 2^(n-1),
+limited to MaxBackoff if it is positive, and shortened by a random fraction up to Jitter.
+If Timeout is positive, each attempt of the methods taking context.Context gets a context with the timeout,
+which is canceled as soon as the attempt returns; results which need the context after the call, such as *sql.Rows,
+cannot be used with Timeout. The retries stop when the caller's context is done.
+OnAttempt, if set, is called after every attempt with its number starting at 1.
+The methods without an error result are passed to Next as they are.`, d.impl.Name(), d.intfRef()))
+
+	d.addMethods(func(m *model.Func) string {
+		if gen.ErrorResult(m.Type()) < 0 {
+			return fmt.Sprintf("%s calls [%s.%s] of Next.", m.Name(), d.intfRef(), m.Name())
+		}
+		return fmt.Sprintf("%s calls [%s.%s] of Next, retrying on error.", m.Name(), d.intfRef(), m.Name())
+	})
+
+	// helpers shared by the methods
+	retry := model.NewMethod(d.rcv, retryName, model.NewTypeSignature(
+		[]*model.Parameter{
+			model.NewParameter("ctx", contextType),
+			model.NewParameter("method", stringType),
+			model.NewParameter("attempt", intType),
+			model.NewParameter("err", gen.ErrorType),
+		},
+		nil,
+		[]*model.Parameter{model.NewParameter("", model.NewTypeBasic("bool"))},
+	), "")
+	retry.SetDoc(retryName + " reports whether the method is called again after the attempt failed with err, waiting for the delay.")
+	d.impl.AddMethod(retry)
+	delay := model.NewMethod(d.rcv, retryDelayName, model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("attempt", intType)},
+		nil,
+		[]*model.Parameter{model.NewParameter("", durationType)},
+	), "")
+	delay.SetDoc(retryDelayName + " returns the delay before the retry of the attempt.")
+	d.impl.AddMethod(delay)
+	withTimeout := model.NewMethod(d.rcv, retryWithTimeoutName, model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("ctx", contextType)},
+		nil,
+		[]*model.Parameter{
+			model.NewParameter("", contextType),
+			model.NewParameter("", model.NewTypeNamed(gen.ContextPkg, "CancelFunc", model.NewTypeSignature(nil, nil, nil))),
+		},
+	), "")
+	withTimeout.SetDoc(retryWithTimeoutName + " returns the context of an attempt.")
+	d.impl.AddMethod(withTimeout)
+
+	file.AddImport(randPkg)
+	file.AddStruct(d.impl)
+	d.addConstructor(file,
+		[]*model.Parameter{
+			model.NewParameter("next", d.intfType),
+			model.NewParameter("maxAttempts", intType),
+			model.NewParameter("backoff", durationType),
+		},
+		nil,
+		[]string{decoratorNextName, retryMaxAttemptsName, retryBackoffName},
+		fmt.Sprintf("New%s returns %s which calls next up to maxAttempts times, doubling the delay from backoff.", d.impl.Name(), d.impl.Name()),
+	)
+
+	return func(pm model.PackageMap) {
+		setRetryStatements(d, retry, delay, withTimeout, pm)
+	}
+}
+
+// setRetryStatements sets the statements of the methods of the decorator retrying the methods returning error.
+func setRetryStatements(d *decorator, retry, delay, withTimeout *model.Method, pm model.PackageMap) {
+	timeQ := gen.Qualifier(pm, timePkg, d.outPkg.Path())
+	contextQ := gen.Qualifier(pm, gen.ContextPkg, d.outPkg.Path())
+	randQ := gen.Qualifier(pm, randPkg, d.outPkg.Path())
+	w := decoratorRcvName
+
+	/*
+		for attempt := 1; ; attempt++ {
+			ctx, cancel := w.withTimeout(a0)
+			r0, r1 := w.Next.Get(ctx, a1)
+			cancel()
+			if !w.retry(a0, "Get", attempt, r1) {
+				return r0, r1
+			}
+		}
+	*/
+	methods := d.impl.Methods()
+	for i, m := range d.intf.Methods() {
+		sig := m.Type()
+		errIndex := gen.ErrorResult(sig)
+		if errIndex < 0 {
+			s := callNext(m, callArgs(sig))
+			if len(sig.Results()) > 0 {
+				s = "return " + s
+			}
+			methods[i].SetStatements(s)
+			continue
+		}
+
+		args := callArgs(sig)
+		ctx := contextQ + "Background()"
+		s := "for attempt := 1; ; attempt++ {\n"
+		if gen.HasContext(sig) {
+			ctx = gen.ArgName(0)
+			args[0] = "ctx"
+			s += "ctx, cancel := " + w + "." + retryWithTimeoutName + "(" + ctx + ")\n"
+		}
+		results := resultNames(sig)
+		s += strings.Join(results, ", ") + " := " + callNext(m, args) + "\n"
+		if gen.HasContext(sig) {
+			s += "cancel()\n"
+		}
+		s += fmt.Sprintf("if !%s.%s(%s, %q, attempt, %s) {\n", w, retryName, ctx, m.Name(), results[errIndex])
+		s += "return " + strings.Join(results, ", ") + "\n"
+		s += "}\n"
+		s += "}"
+		methods[i].SetStatements(s)
+	}
+
+	/*
+		if w.OnAttempt != nil {
+			w.OnAttempt(method, attempt, err)
+		}
+		if err == nil || attempt >= w.MaxAttempts || (w.Retryable != nil && !w.Retryable(err)) {
+			return false
+		}
+		t := time.NewTimer(w.delay(attempt))
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return false
+		case <-t.C:
+			return true
+		}
+	*/
+	s := "if " + w + "." + retryOnAttemptName + " != nil {\n"
+	s += w + "." + retryOnAttemptName + "(method, attempt, err)\n"
+	s += "}\n"
+	s += "if err == nil || attempt >= " + w + "." + retryMaxAttemptsName + " || (" + w + "." + retryRetryableName + " != nil && !" + w + "." + retryRetryableName + "(err)) {\n"
+	s += "return false\n"
+	s += "}\n"
+	s += "t := " + timeQ + "NewTimer(" + w + "." + retryDelayName + "(attempt))\n"
+	s += "defer t.Stop()\n"
+	s += "select {\n"
+	s += "case <-ctx.Done():\n"
+	s += "return false\n"
+	s += "case <-t.C:\n"
+	s += "return true\n"
+	s += "}"
+	retry.SetStatements(s)
+
+	/*
+		d := w.Backoff
+		for i := 1; i < attempt && (w.MaxBackoff <= 0 || d < w.MaxBackoff); i++ {
+			d *= 2
+		}
+		if w.MaxBackoff > 0 && d > w.MaxBackoff {
+			d = w.MaxBackoff
+		}
+		if w.Jitter > 0 {
+			d -= time.Duration(w.Jitter * rand.Float64() * float64(d))
+		}
+		return d
+	*/
+	s = "d := " + w + "." + retryBackoffName + "\n"
+	s += "for i := 1; i < attempt && (" + w + "." + retryMaxBackoffName + " <= 0 || d < " + w + "." + retryMaxBackoffName + "); i++ {\n"
+	s += "d *= 2\n"
+	s += "}\n"
+	s += "if " + w + "." + retryMaxBackoffName + " > 0 && d > " + w + "." + retryMaxBackoffName + " {\n"
+	s += "d = " + w + "." + retryMaxBackoffName + "\n"
+	s += "}\n"
+	s += "if " + w + "." + retryJitterName + " > 0 {\n"
+	s += "d -= " + timeQ + "Duration(" + w + "." + retryJitterName + " * " + randQ + "Float64() * float64(d))\n"
+	s += "}\n"
+	s += "return d"
+	delay.SetStatements(s)
+
+	/*
+		if w.Timeout <= 0 {
+			return ctx, func() {}
+		}
+		return context.WithTimeout(ctx, w.Timeout)
+	*/
+	s = "if " + w + "." + retryTimeoutName + " <= 0 {\n"
+	s += "return ctx, func() {}\n"
+	s += "}\n"
+	s += "return " + contextQ + "WithTimeout(ctx, " + w + "." + retryTimeoutName + ")"
+	withTimeout.SetStatements(s)
+}
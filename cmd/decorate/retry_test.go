@@ -0,0 +1,70 @@
+package decorate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestRetryDecorator(t *testing.T) {
+	pkg, intf := newTestInterface()
+	src := generate(t, pkg, intf, KindRetry, model.NewPkgInfo("app", "example.com/app", ""), options{})
+
+	expects := []string{
+		"type RetryingUserService struct {",
+		"Next        UserService",
+		"MaxAttempts int",
+		"Jitter      float64",
+		"Retryable   func(err error) bool",
+		"OnAttempt   func(method string, attempt int, err error)",
+		// each attempt gets the context with the timeout, and the retries follow the caller's context
+		"ctx, cancel := w.withTimeout(a0)",
+		"r0, r1 := w.Next.Get(ctx, a1)",
+		// the context of every attempt is canceled as soon as it returns
+		"r0, r1 := w.Next.Get(ctx, a1)\n\t\tcancel()\n\t\tif !w.retry(a0, \"Get\", attempt, r1) {\n\t\t\treturn r0, r1\n\t\t}\n\t}",
+		// methods without error are passed through
+		"func (w *RetryingUserService) Notify(a0 ...string) {\n\tw.Next.Notify(a0...)\n}",
+		"d -= time.Duration(w.Jitter * rand.Float64() * float64(d))",
+		"return context.WithTimeout(ctx, w.Timeout)",
+		"func NewRetryingUserService(next UserService, maxAttempts int, backoff time.Duration) *RetryingUserService {",
+		"return &RetryingUserService{Next: next, MaxAttempts: maxAttempts, Backoff: backoff}",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(src, expect) {
+			t.Errorf("retryDecorator() missing %q\n%s", expect, src)
+		}
+	}
+}
+
+func TestRetryDecoratorWithoutContext(t *testing.T) {
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	pkg := &model.Package{
+		Name:         "app",
+		Path:         "example.com/app",
+		Dependencies: model.NewPackageMap("app", "example.com/app"),
+	}
+	intf := model.NewInterface("Sender", appPkg, []*model.Func{
+		model.NewFunc("Send", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("msg", model.NewTypeBasic("string"))},
+			nil,
+			[]*model.Parameter{model.NewParameter("", gen.ErrorType)},
+		), ""),
+	})
+	src := generate(t, pkg, intf, KindRetry, model.NewPkgInfo("retry", "example.com/app/retry", ""), options{})
+
+	expects := []string{
+		"Next        app.Sender",
+		"r0 := w.Next.Send(a0)",
+		`if !w.retry(context.Background(), "Send", attempt, r0) {`,
+	}
+	for _, expect := range expects {
+		if !strings.Contains(src, expect) {
+			t.Errorf("retryDecorator() missing %q\n%s", expect, src)
+		}
+	}
+	if strings.Contains(src, "cancel()") {
+		t.Errorf("retryDecorator() should not time out the methods without context\n%s", src)
+	}
+}
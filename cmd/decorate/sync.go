@@ -0,0 +1,80 @@
+package decorate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+const syncMutexName = "mu"
+
+func getSynchronizedName(intfName string) string {
+	return "Synchronized" + intfName
+}
+
+// syncDecorator adds the fields and the methods of the decorator serializing every call to d,
+// and returns the func setting the statements.
+// The methods in readOnly hold the read lock of sync.RWMutex instead, so that they can run concurrently.
+func syncDecorator(d *decorator, file *model.File, readOnly []string) (func(pm model.PackageMap), error) {
+	ro := map[string]bool{}
+	for _, name := range readOnly {
+		if findMethod(d.intf, name) == nil {
+			return nil, fmt.Errorf("%s is not a method of %s", name, d.intf.Name())
+		}
+		ro[name] = true
+	}
+
+	mutex := "Mutex"
+	doc := fmt.Sprintf("%s wraps [%s] and serializes the calls with a mutex, so that Next can be shared by goroutines.", d.impl.Name(), d.intfRef())
+	if len(ro) > 0 {
+		mutex = "RWMutex"
+		doc += fmt.Sprintf("\nThe read-only methods %s hold the read lock, and can run concurrently with each other.", strings.Join(readOnly, ", "))
+	}
+	d.addNext()
+	d.impl.AddField(model.NewField(syncMutexName, model.NewTypeNamed(syncPkg, mutex, model.NewTypeStruct(nil)), ""))
+	d.impl.SetDoc(doc)
+
+	d.addMethods(func(m *model.Func) string {
+		if ro[m.Name()] {
+			return fmt.Sprintf("%s calls [%s.%s] of Next holding the read lock.", m.Name(), d.intfRef(), m.Name())
+		}
+		return fmt.Sprintf("%s calls [%s.%s] of Next holding the lock.", m.Name(), d.intfRef(), m.Name())
+	})
+
+	file.AddStruct(d.impl)
+	d.addConstructor(file,
+		[]*model.Parameter{model.NewParameter("next", d.intfType)},
+		nil,
+		[]string{decoratorNextName},
+		fmt.Sprintf("New%s returns %s which serializes the calls to next.", d.impl.Name(), d.impl.Name()),
+	)
+
+	return func(pm model.PackageMap) {
+		setSyncStatements(d, ro)
+	}, nil
+}
+
+// setSyncStatements sets the statements of the methods of the decorator serializing every call.
+func setSyncStatements(d *decorator, readOnly map[string]bool) {
+	/*
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+		return w.Next.Get(a0, a1)
+	*/
+	mu := decoratorRcvName + "." + syncMutexName
+	methods := d.impl.Methods()
+	for i, m := range d.intf.Methods() {
+		lock, unlock := "Lock", "Unlock"
+		if readOnly[m.Name()] {
+			lock, unlock = "RLock", "RUnlock"
+		}
+		s := mu + "." + lock + "()\n"
+		s += "defer " + mu + "." + unlock + "()\n"
+		if len(m.Type().Results()) > 0 {
+			s += "return "
+		}
+		s += callNext(m, callArgs(m.Type()))
+		methods[i].SetStatements(s)
+	}
+}
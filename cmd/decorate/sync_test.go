@@ -0,0 +1,47 @@
+package decorate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestSyncDecorator(t *testing.T) {
+	pkg, intf := newTestInterface()
+	src := generate(t, pkg, intf, KindSync, model.NewPkgInfo("app", "example.com/app", ""), options{})
+
+	expects := []string{
+		"type SynchronizedUserService struct {\n\tNext UserService\n\tmu   sync.Mutex\n}",
+		"func (w *SynchronizedUserService) Get(a0 context.Context, a1 string) (*User, error) {\n\tw.mu.Lock()\n\tdefer w.mu.Unlock()\n\treturn w.Next.Get(a0, a1)\n}",
+		"func (w *SynchronizedUserService) Notify(a0 ...string) {\n\tw.mu.Lock()\n\tdefer w.mu.Unlock()\n\tw.Next.Notify(a0...)\n}",
+		"func NewSynchronizedUserService(next UserService) *SynchronizedUserService {",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(src, expect) {
+			t.Errorf("syncDecorator() missing %q\n%s", expect, src)
+		}
+	}
+}
+
+func TestSyncDecoratorReadOnly(t *testing.T) {
+	pkg, intf := newTestInterface()
+	src := generate(t, pkg, intf, KindSync, model.NewPkgInfo("app", "example.com/app", ""), options{readOnly: []string{"Get"}})
+
+	expects := []string{
+		"mu   sync.RWMutex",
+		"// The read-only methods Get hold the read lock, and can run concurrently with each other.",
+		"w.mu.RLock()\n\tdefer w.mu.RUnlock()\n\treturn w.Next.Get(a0, a1)",
+		"w.mu.Lock()\n\tdefer w.mu.Unlock()\n\tw.Next.Notify(a0...)",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(src, expect) {
+			t.Errorf("syncDecorator() missing %q\n%s", expect, src)
+		}
+	}
+
+	_, err := decoratorFile(pkg, intf, KindSync, "", model.NewPkgInfo("app", "example.com/app", ""), options{readOnly: []string{"List"}})
+	if err == nil || err.Error() != "List is not a method of UserService" {
+		t.Errorf("decoratorFile() error = %v, want %v", err, "List is not a method of UserService")
+	}
+}
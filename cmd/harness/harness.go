@@ -0,0 +1,385 @@
+package harness
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kmio11/codegen/cmd/mock"
+	"github.com/kmio11/codegen/generator"
+	"github.com/kmio11/codegen/generator/model"
+	"github.com/kmio11/codegen/generator/parser"
+)
+
+var testingPkg = model.NewPkgInfo("testing", "testing", "")
+
+// Command implements the test harness generation command
+type Command struct {
+	fs       *flag.FlagSet
+	flagPkg  *string
+	flagType *string
+	flagOut  *string
+}
+
+// New creates a new harness command
+func New() *Command {
+	c := &Command{}
+	c.fs = flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	c.flagPkg = c.fs.String("pkg", ".", "The package containing the struct to generate test harness for.")
+	c.flagType = c.fs.String("type", "", "The name of the struct type to generate test harness for.")
+	c.flagOut = c.fs.String("out", "", "Output file; defaults to stdout.")
+
+	return c
+}
+
+// Name returns the command name
+func (c Command) Name() string {
+	return "harness"
+}
+
+// Description returns the command description
+func (c Command) Description() string {
+	return "generate test harness for struct with interface dependencies"
+}
+
+// Usage prints usage information
+func (c Command) Usage(cmd string) {
+	fmt.Printf(`Usage:
+	%s %s [flags]
+
+Generate mocks for every interface-typed field of a struct,
+and a constructor which builds the struct with all mocks injected.
+The harness is generated into the package of the struct.
+
+Examples:
+	# Generate harness for OrderService
+	%s %s -pkg . -type OrderService -out order_service_harness_test.go
+
+Flags:
+`, cmd, c.Name(), cmd, c.Name())
+	c.fs.PrintDefaults()
+}
+
+// Parse parses command line arguments
+func (c *Command) Parse(args []string) error {
+	err := c.fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	// Validate required flags
+	if *c.flagType == "" {
+		return fmt.Errorf("-type flag is required")
+	}
+
+	return nil
+}
+
+// Execute runs the harness generation command
+func (c *Command) Execute() int {
+	// Parse the package
+	p, targetPkg, targetStruct, err := c.parse(*c.flagType, *c.flagPkg)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	// the mocks may be declared in the _test.go files, which are not loaded.
+	testNames, err := testDecls(p.ParsedPkg, *c.flagOut)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	// Create output file
+	file, err := harnessFile(targetPkg, targetStruct, *c.flagOut, func(name string) bool {
+		return p.ParsedPkg.Pkg.Scope().Lookup(name) != nil || testNames[name]
+	})
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	// Generate code
+	g := &generator.Generator{}
+	src := g.
+		PrintHeader(c.Name()).
+		Printf("// Harness for %s.%s", targetPkg.Path, targetStruct.Name()).
+		NewLine().
+		Printf("%s", file.PrintCode()).
+		Format()
+
+	// Output
+	if file.Path() == "" {
+		fmt.Println(string(src))
+	} else {
+		err := os.WriteFile(file.Path(), src, 0644)
+		if err != nil {
+			log.Printf("writing output: %s\n", err)
+			return 1
+		}
+		fmt.Printf("File created successfully : %s\n", file.Path())
+	}
+
+	return 0
+}
+
+// parse parses the package and extracts the target struct
+func (c *Command) parse(typ string, pkg string) (*parser.Parser, *model.Package, *model.Struct, error) {
+	// Create parser
+	p := parser.NewParser(
+		parser.OptLogger(log.New(os.Stderr, "", log.LstdFlags|log.Lshortfile)),
+		parser.OptParseTarget([]string{typ}),
+	)
+
+	// Load package
+	err := p.LoadPackage(pkg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Parse the package
+	targetPkg, err := p.Parse()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Check if struct was parsed
+	if len(targetPkg.Structs) == 0 {
+		return nil, nil, nil, fmt.Errorf("%s is not struct", typ)
+	}
+
+	return p, targetPkg, targetPkg.Structs[0], nil
+}
+
+// testDecls returns the names declared at the package level of the _test.go files of pkg.
+// The files of the external test package and outFile, which is overwritten, are excluded.
+func testDecls(pkg *parser.Package, outFile string) (map[string]bool, error) {
+	names := map[string]bool{}
+	if len(pkg.GoFiles) == 0 {
+		return names, nil
+	}
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(pkg.GoFiles[0]), "*_test.go"))
+	if err != nil {
+		return nil, err
+	}
+	out := ""
+	if outFile != "" {
+		out, err = filepath.Abs(outFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if name == out {
+			continue
+		}
+		f, err := goparser.ParseFile(fset, name, nil, goparser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if f.Name.Name != pkg.Name {
+			continue
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					names[d.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						names[s.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, n := range s.Names {
+							names[n.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+// dependency is an interface-typed field of the target struct.
+type dependency struct {
+	field    string // field name of the target struct
+	name     string // field name of the harness
+	intf     *model.Interface
+	mockName string
+}
+
+// dependencies returns interface-typed fields of s.
+// Fields whose type is a predeclared or method-less interface are skipped.
+// An instantiated generic interface gets the mock named after its type arguments, such as MockCacheStringInt for Cache[string, int];
+// the field is skipped with a log if a type argument is neither a basic nor a named type.
+func dependencies(s *model.Struct) []*dependency {
+	deps := []*dependency{}
+	for _, f := range s.Fields() {
+		named, ok := f.Type().(*model.TypeNamed)
+		if !ok || named.Pkg() == nil || named.IsGeneric() {
+			continue
+		}
+		intfType, ok := named.Org().(*model.TypeInterface)
+		if !ok || len(intfType.Methods()) == 0 {
+			continue
+		}
+
+		field := f.Name()
+		if field == "" {
+			field = named.Name() // embedded field
+		}
+		args, ok := typeArgsName(named.TypeArgs())
+		if !ok {
+			log.Printf("field %s of %s is skipped: the mock of %s cannot be named after its type arguments\n", field, s.Name(), named.Name())
+			continue
+		}
+		deps = append(deps, &dependency{
+			field:    field,
+			name:     upperFirst(field),
+			intf:     model.NewInstantiatedInterface(named.Name(), named.Pkg(), intfType.Methods(), named.TypeArgs()),
+			mockName: "Mock" + named.Name() + args,
+		})
+	}
+	return deps
+}
+
+// typeArgsName returns the concatenated names of the type arguments, such as "StringInt" for [string, int].
+// It returns false if an argument is neither a basic nor a named type.
+func typeArgsName(args []model.Type) (string, bool) {
+	name := ""
+	for _, arg := range args {
+		switch t := arg.(type) {
+		case *model.TypeBasic:
+			name += upperFirst(t.PrintType("", model.PackageMap{}))
+		case *model.TypeNamed:
+			s, ok := typeArgsName(t.TypeArgs())
+			if !ok {
+				return "", false
+			}
+			name += upperFirst(t.Name()) + s
+		default:
+			return "", false
+		}
+	}
+	return name, true
+}
+
+// harnessFile returns the file which has mocks and harness of targetStruct.
+// exists reports whether the name is already declared in the package,
+// in which case the declared mock is used.
+func harnessFile(targetPkg *model.Package, targetStruct *model.Struct, outFile string, exists func(name string) bool) (*model.File, error) {
+	if targetStruct.IsGeneric() {
+		return nil, fmt.Errorf("generic struct %s is unsupported", targetStruct.Name())
+	}
+	outPkg := model.NewPkgInfo(targetPkg.Name, targetPkg.Path, "")
+
+	file := model.NewFile(outFile, targetPkg.Name, targetPkg.Path, targetPkg.CopyDependencies())
+	file.DependenciesTidy()
+
+	// create mocks
+	deps := dependencies(targetStruct)
+	mocks := map[string]*model.Interface{}
+	for _, dep := range deps {
+		if m, ok := mocks[dep.mockName]; ok {
+			if m.Type().Pkg().Path() != dep.intf.Type().Pkg().Path() {
+				return nil, fmt.Errorf("%s is duplicated: %s.%s and %s.%s",
+					dep.mockName, m.Type().Pkg().Path(), m.Name(), dep.intf.Type().Pkg().Path(), dep.intf.Name())
+			}
+			continue
+		}
+		mocks[dep.mockName] = dep.intf
+		if exists(dep.mockName) {
+			continue
+		}
+		// the mock cannot implement the methods referring to unexported types of other packages.
+		if _, err := generator.FilterUnexported(dep.intf, targetPkg.Path, generator.UnexportedError, log.Default()); err != nil {
+			return nil, fmt.Errorf("field %s of %s: %w", dep.field, targetStruct.Name(), err)
+		}
+		file.AddStruct(mock.Struct(dep.mockName, dep.intf, outPkg))
+	}
+
+	// create harness
+	harnessName := lowerFirst(targetStruct.Name()) + "Harness"
+	harness := model.NewStruct(harnessName, outPkg)
+	harness.AddField(model.NewField(targetStruct.Name(), model.NewPointer(targetStruct.Type()), ""))
+	for _, dep := range deps {
+		mockType := model.NewTypeNamed(outPkg, dep.mockName, model.NewTypeStruct([]*model.Field{}))
+		harness.AddField(model.NewField(dep.name, model.NewPointer(mockType), ""))
+	}
+	file.AddStruct(harness)
+
+	// create constructor
+	/*
+		func newXxxHarness(t testing.TB) *xxxHarness {
+			t.Helper()
+			h := &xxxHarness{
+				Repo: &MockRepo{},
+			}
+			h.Xxx = &Xxx{
+				repo: h.Repo,
+			}
+			return h
+		}
+	*/
+	body := "t.Helper()\n"
+	body += "h := &" + harnessName + "{\n"
+	for _, dep := range deps {
+		body += dep.name + ": &" + dep.mockName + "{},\n"
+	}
+	body += "}\n"
+	body += "h." + targetStruct.Name() + " = &" + targetStruct.Name() + "{\n"
+	for _, dep := range deps {
+		body += dep.field + ": h." + dep.name + ",\n"
+	}
+	body += "}\n"
+	body += "return h"
+
+	constructor := model.NewFunc(
+		"new"+targetStruct.Name()+"Harness",
+		model.NewTypeSignature(
+			[]*model.Parameter{
+				model.NewParameter("t", model.NewTypeNamed(testingPkg, "TB", model.NewTypeInterface(nil, nil))),
+			},
+			nil,
+			[]*model.Parameter{
+				model.NewParameter("", model.NewPointer(harness.Type())),
+			},
+		),
+		body,
+	)
+	file.AddFunc(constructor)
+
+	file.DependenciesTidy()
+	return file, nil
+}
+
+func upperFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+func lowerFirst(s string) string {
+	// keep initialisms such as "HTTPClient" readable: "httpClient"
+	i := 0
+	for i < len(s) && unicode.IsUpper(rune(s[i])) {
+		i++
+	}
+	if i > 1 && i < len(s) {
+		i--
+	}
+	return strings.ToLower(s[:i]) + s[i:]
+}
@@ -0,0 +1,266 @@
+package harness
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+	"github.com/kmio11/codegen/generator/parser"
+)
+
+// TestNew tests harness command creation
+func TestNew(t *testing.T) {
+	cmd := New()
+	if cmd == nil {
+		t.Fatal("New() returned nil")
+	}
+
+	if cmd.Name() != "harness" {
+		t.Errorf("Expected command name 'harness', got %s", cmd.Name())
+	}
+}
+
+// TestParse tests command flag parsing and validation
+func TestParse(t *testing.T) {
+	cmd := New()
+	if err := cmd.Parse([]string{"-pkg", ".", "-type", "OrderService"}); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	cmd2 := New()
+	if err := cmd2.Parse([]string{"-pkg", "."}); err == nil {
+		t.Error("Parse() should fail when -type flag is missing")
+	}
+}
+
+func newHarnessTestStruct(busPkgPath string) (*model.Package, *model.Struct) {
+	pkg := &model.Package{
+		Name:         "app",
+		Path:         "example.com/app",
+		Dependencies: model.NewPackageMap("app", "example.com/app"),
+	}
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	eventsPkg := model.NewPkgInfo("events", busPkgPath, "")
+
+	repo := model.NewTypeNamed(appPkg, "Repo", model.NewTypeInterface(nil, []*model.Func{
+		model.NewFunc("Save", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("id", model.NewTypeBasic("string"))},
+			nil,
+			[]*model.Parameter{model.NewParameter("", model.NewTypeNamed(nil, "error", model.NewTypeInterface(nil, nil)))},
+		), ""),
+	}))
+	bus := model.NewTypeNamed(eventsPkg, "Bus", model.NewTypeInterface(nil, []*model.Func{
+		model.NewFunc("Publish", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("name", model.NewTypeBasic("string"))},
+			nil,
+			nil,
+		), ""),
+	}))
+
+	s := model.NewStruct("OrderService", appPkg)
+	s.AddField(model.NewField("repo", repo, ""))
+	s.AddField(model.NewField("bus", bus, ""))
+	s.AddField(model.NewField("name", model.NewTypeBasic("string"), ""))
+	s.AddField(model.NewField("err", model.NewTypeNamed(nil, "error", model.NewTypeInterface(nil, nil)), ""))
+	return pkg, s
+}
+
+func TestHarnessFile(t *testing.T) {
+	pkg, s := newHarnessTestStruct("example.com/app/events")
+
+	file, err := harnessFile(pkg, s, "", func(string) bool { return false })
+	if err != nil {
+		t.Fatalf("harnessFile() error = %v", err)
+	}
+	code := file.PrintCode()
+	src, err := format.Source([]byte(code))
+	if err != nil {
+		t.Fatalf("harnessFile() generated invalid code: %v\n%s", err, code)
+	}
+
+	for _, c := range []string{
+		`"example.com/app/events"`,
+		`"testing"`,
+		"type MockRepo struct",
+		"type MockBus struct",
+		"events.Bus",
+		"type orderServiceHarness struct",
+		"Repo         *MockRepo",
+		"func newOrderServiceHarness(t testing.TB) *orderServiceHarness",
+		"bus:  h.Bus,",
+	} {
+		if !strings.Contains(string(src), c) {
+			t.Errorf("harnessFile() should contain %q\n%s", c, src)
+		}
+	}
+	for _, c := range []string{"MockError", "name:"} {
+		if strings.Contains(string(src), c) {
+			t.Errorf("harnessFile() should not contain %q\n%s", c, src)
+		}
+	}
+}
+
+func TestHarnessFileExistingMock(t *testing.T) {
+	pkg, s := newHarnessTestStruct("example.com/app/events")
+
+	file, err := harnessFile(pkg, s, "", func(name string) bool { return name == "MockRepo" })
+	if err != nil {
+		t.Fatalf("harnessFile() error = %v", err)
+	}
+	code := file.PrintCode()
+	if strings.Contains(code, "type MockRepo struct") {
+		t.Errorf("harnessFile() should use declared mock\n%s", code)
+	}
+	if !strings.Contains(code, "&MockRepo{}") {
+		t.Errorf("harnessFile() should inject declared mock\n%s", code)
+	}
+}
+
+func TestHarnessFileUnexported(t *testing.T) {
+	pkg, s := newHarnessTestStruct("example.com/app/events")
+	eventsPkg := model.NewPkgInfo("events", "example.com/app/events", "")
+	topic := model.NewTypeNamed(eventsPkg, "topic", model.NewTypeBasic("string"))
+	s.AddField(model.NewField("sub", model.NewTypeNamed(eventsPkg, "Subscriber", model.NewTypeInterface(nil, []*model.Func{
+		model.NewFunc("Subscribe", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("t", topic)},
+			nil,
+			nil,
+		), ""),
+	})), ""))
+
+	if _, err := harnessFile(pkg, s, "", func(string) bool { return false }); err == nil {
+		t.Error("harnessFile() should fail if a dependency refers to unexported types of another package")
+	}
+	if _, err := harnessFile(pkg, s, "", func(name string) bool { return name == "MockSubscriber" }); err != nil {
+		t.Errorf("harnessFile() should use declared mock: %v", err)
+	}
+}
+
+func TestTestDecls(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"app.go":                        "package app\n\ntype OrderService struct{}\n",
+		"order_harness_test.go":         "package app\n\ntype MockRepo struct{}\n\nfunc (m *MockRepo) Save() {}\n",
+		"user_harness_test.go":          "package app\n\ntype MockUser struct{}\n",
+		"app_test.go":                   "package app_test\n\ntype MockBus struct{}\n",
+		"order_service_harness_test.go": "package app\n\nvar mockClock, mockNow int\n\nfunc newHarness() {}\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pkg := &parser.Package{Name: "app", GoFiles: []string{filepath.Join(dir, "app.go")}}
+
+	names, err := testDecls(pkg, filepath.Join(dir, "user_harness_test.go"))
+	if err != nil {
+		t.Fatalf("testDecls() error = %v", err)
+	}
+	for _, n := range []string{"MockRepo", "mockClock", "mockNow", "newHarness"} {
+		if !names[n] {
+			t.Errorf("testDecls() should contain %s: %v", n, names)
+		}
+	}
+	// the output file is overwritten, and the external test package is another package.
+	for _, n := range []string{"MockUser", "MockBus", "OrderService", "Save"} {
+		if names[n] {
+			t.Errorf("testDecls() should not contain %s: %v", n, names)
+		}
+	}
+}
+
+func TestHarnessFileInstantiatedInterface(t *testing.T) {
+	pkg, s := newHarnessTestStruct("example.com/app/events")
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	get := func(k, v model.Type) []*model.Func {
+		return []*model.Func{
+			model.NewFunc("Get", model.NewTypeSignature(
+				[]*model.Parameter{model.NewParameter("k", k)},
+				nil,
+				[]*model.Parameter{model.NewParameter("", v)},
+			), ""),
+		}
+	}
+	user := model.NewTypeNamed(appPkg, "User", model.NewTypeStruct([]*model.Field{}))
+	s.AddField(model.NewField("cache", model.NewInstantiatedTypeNamed(appPkg, "Cache",
+		model.NewTypeInterface(nil, get(model.NewTypeBasic("string"), user)),
+		[]model.Type{model.NewTypeBasic("string"), user},
+	), ""))
+	bytes := model.NewTypeArray(-1, model.NewTypeBasic("byte"))
+	s.AddField(model.NewField("blobs", model.NewInstantiatedTypeNamed(appPkg, "Cache",
+		model.NewTypeInterface(nil, get(model.NewTypeBasic("string"), bytes)),
+		[]model.Type{model.NewTypeBasic("string"), bytes},
+	), ""))
+
+	file, err := harnessFile(pkg, s, "", func(string) bool { return false })
+	if err != nil {
+		t.Fatalf("harnessFile() error = %v", err)
+	}
+	code := file.PrintCode()
+	src, err := format.Source([]byte(code))
+	if err != nil {
+		t.Fatalf("harnessFile() generated invalid code: %v\n%s", err, code)
+	}
+
+	for _, c := range []string{
+		"type MockCacheStringUser struct",
+		"Cache[string, User]",
+		"FakeGet func(k string) User",
+		"Cache        *MockCacheStringUser",
+		"cache: h.Cache,",
+	} {
+		if !strings.Contains(string(src), c) {
+			t.Errorf("harnessFile() should contain %q\n%s", c, src)
+		}
+	}
+	if strings.Contains(string(src), "blobs") {
+		t.Errorf("harnessFile() should skip the field whose type arguments have no name\n%s", src)
+	}
+}
+
+func TestTypeArgsName(t *testing.T) {
+	pkg := model.NewPkgInfo("app", "example.com/app", "")
+	tests := []struct {
+		name     string
+		args     []model.Type
+		expected string
+		ok       bool
+	}{
+		{"none", nil, "", true},
+		{"basic", []model.Type{model.NewTypeBasic("string"), model.NewTypeBasic("int")}, "StringInt", true},
+		{"named", []model.Type{model.NewInstantiatedTypeNamed(pkg, "Page", nil, []model.Type{model.NewTypeBasic("int")})}, "PageInt", true},
+		{"slice", []model.Type{model.NewTypeArray(-1, model.NewTypeBasic("byte"))}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := typeArgsName(tt.args)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("typeArgsName() = %v, %v, want %v, %v", got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLowerFirst(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"OrderService", "orderService"},
+		{"HTTPClient", "httpClient"},
+		{"DB", "db"},
+		{"x", "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := lowerFirst(tt.in); got != tt.expected {
+				t.Errorf("lowerFirst(%v) = %v, want %v", tt.in, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package ifacecommand
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+const (
+	adapterRcvName   = "w"
+	adapterFieldName = "impl"
+)
+
+// getAdapterName returns the name of the adapter of the struct, such as SQLDB for sql.DB.
+func getAdapterName(pkgName, structName string) string {
+	return strings.ToUpper(pkgName) + structName
+}
+
+// createAdapterFile creates a file containing the interfaces of the structs and their adapters.
+// intfs[i] is the interface of targetPkg.Structs[i].
+func (c *Command) createAdapterFile(targetPkg *model.Package, intfs []*model.Interface, outFile, outPkgName, selfPkgPath string) *model.File {
+	// Determine output package
+	outPkgName, outPkgPath := gen.OutPackage(targetPkg, outFile, outPkgName, selfPkgPath)
+	outPkg := model.NewPkgInfo(outPkgName, outPkgPath, "")
+
+	// Create file
+	file := model.NewFile(outFile, outPkgName, outPkgPath, targetPkg.CopyDependencies())
+	addAdapters(file, targetPkg.Structs, intfs, outPkg)
+	file.DependenciesTidy()
+
+	return file
+}
+
+// addAdapters adds the interfaces of the structs and the adapters implementing them by forwarding to the structs.
+// intfs[i] is the interface of structs[i]. The methods return the pointers to the structs
+// as their interfaces, wrapped by their adapters, so that the whole object graph can be faked.
+func addAdapters(file *model.File, structs []*model.Struct, intfs []*model.Interface, outPkg *model.PkgInfo) {
+	// the interfaces are declared in the output package
+	index := map[string]int{}
+	intfTypes := []*model.TypeNamed{}
+	for i, st := range structs {
+		index[st.Type().Pkg().Path()+"."+st.Name()] = i
+		intfTypes = append(intfTypes, model.NewTypeNamed(outPkg, intfs[i].Name(), intfs[i].Type().Org()))
+	}
+	wrapped := func(t model.Type) (int, bool) {
+		ptr, ok := t.(*model.TypePointer)
+		if !ok {
+			return 0, false
+		}
+		named, ok := ptr.Type().(*model.TypeNamed)
+		if !ok || named.Pkg() == nil {
+			return 0, false
+		}
+		i, ok := index[named.Pkg().Path()+"."+named.Name()]
+		return i, ok
+	}
+
+	adapters := []*model.Struct{}
+	for i, st := range structs {
+		adapterName := getAdapterName(st.Type().Pkg().Name(), st.Name())
+		adapter := model.NewStruct(adapterName, outPkg)
+		adapter.AddField(model.NewField(adapterFieldName, model.NewPointer(st.Type()), ""))
+		structRef := st.Type().Pkg().Prefix(outPkg.Path()) + st.Name()
+		adapter.SetDoc(fmt.Sprintf("%s adapts [%s] to %s by forwarding every method.", adapterName, structRef, intfs[i].Name()))
+		rcv := model.NewParameter(adapterRcvName, model.NewPointer(adapter.Type()))
+
+		methods := []*model.Func{}
+		for _, m := range intfs[i].Methods() {
+			// the results pointing to the structs are returned as their interfaces.
+			results := []*model.Parameter{}
+			adapterResults := []*model.Parameter{}
+			for _, r := range m.Type().Results() {
+				typ := r.Type()
+				if j, ok := wrapped(typ); ok {
+					typ = intfTypes[j]
+				}
+				results = append(results, model.NewParameter(r.Name(), typ))
+				adapterResults = append(adapterResults, model.NewParameter("", typ))
+			}
+			method := model.NewFunc(m.Name(), model.NewTypeSignature(m.Type().Args(), m.Type().Variadic(), results), "")
+			method.SetDoc(m.Doc())
+			methods = append(methods, method)
+
+			adapterMethod := model.NewMethod(rcv, m.Name(), argNamedSignature(m.Type(), adapterResults), forwardStatements(m, structs, intfs, wrapped))
+			adapterMethod.SetDoc(fmt.Sprintf("%s calls [%s.%s].", m.Name(), structRef, m.Name()))
+			adapter.AddMethod(adapterMethod)
+		}
+
+		intf := model.NewInterface(intfs[i].Name(), outPkg, methods)
+		intf.SetDoc(structIntfDoc(intf.Name(), structs[i:i+1], outPkg))
+		file.AddInterface(intf)
+		adapters = append(adapters, adapter)
+	}
+
+	for i, adapter := range adapters {
+		file.AddStruct(adapter)
+
+		// NewXxx returns the adapter of the struct.
+		newAdapter := model.NewFunc(
+			"New"+adapter.Name(),
+			model.NewTypeSignature(
+				[]*model.Parameter{model.NewParameter(adapterFieldName, model.NewPointer(structs[i].Type()))},
+				nil,
+				[]*model.Parameter{model.NewParameter("", model.NewPointer(adapter.Type()))},
+			),
+			fmt.Sprintf("return &%s{%s: %s}", adapter.Name(), adapterFieldName, adapterFieldName),
+		)
+		newAdapter.SetDoc(fmt.Sprintf("%s returns %s forwarding to %s.", newAdapter.Name(), adapter.Name(), adapterFieldName))
+		file.AddFunc(newAdapter)
+	}
+	for i, adapter := range adapters {
+		file.AddAssertion(model.NewAssertion(intfTypes[i], adapter.Type(), true))
+	}
+}
+
+// forwardStatements returns the statements of the adapter method forwarding to the struct.
+func forwardStatements(m *model.Func, structs []*model.Struct, intfs []*model.Interface, wrapped func(model.Type) (int, bool)) string {
+	/*
+		return w.impl.Exec(a0, a1...)
+
+		r0, r1 := w.impl.Begin()
+		var v0 TxInterface
+		if r0 != nil {
+			v0 = &SQLTx{impl: r0}
+		}
+		return v0, r1
+	*/
+	args := []string{}
+	for i := range m.Type().Args() {
+		args = append(args, getArgName(i))
+	}
+	if m.Type().Variadic() != nil {
+		args = append(args, getArgName(len(args))+"...")
+	}
+	call := adapterRcvName + "." + adapterFieldName + "." + m.Name() + "(" + strings.Join(args, ", ") + ")"
+
+	results := m.Type().Results()
+	hasWrapped := false
+	for _, r := range results {
+		if _, ok := wrapped(r.Type()); ok {
+			hasWrapped = true
+		}
+	}
+	if len(results) == 0 {
+		return call
+	}
+	if !hasWrapped {
+		return "return " + call
+	}
+
+	vars := []string{}
+	rets := []string{}
+	s := ""
+	for i, r := range results {
+		v := "r" + strconv.Itoa(i)
+		vars = append(vars, v)
+		j, ok := wrapped(r.Type())
+		if !ok {
+			rets = append(rets, v)
+			continue
+		}
+		// a nil pointer is returned as the nil interface.
+		w := "v" + strconv.Itoa(i)
+		s += fmt.Sprintf("var %s %s\nif %s != nil {\n%s = &%s{%s: %s}\n}\n",
+			w, intfs[j].Name(), v, w, getAdapterName(structs[j].Type().Pkg().Name(), structs[j].Name()), adapterFieldName, v)
+		rets = append(rets, w)
+	}
+	return strings.Join(vars, ", ") + " := " + call + "\n" + s + "return " + strings.Join(rets, ", ")
+}
@@ -0,0 +1,59 @@
+package ifacecommand
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestAdapters(t *testing.T) {
+	sqlPkg := model.NewPkgInfo("sql", "database/sql", "")
+	outPkg := model.NewPkgInfo("store", "example.com/store", "")
+	errType := model.NewTypeBasic("error")
+
+	db := model.NewStruct("DB", sqlPkg)
+	tx := model.NewStruct("Tx", sqlPkg)
+	dbIntf := model.NewInterface("DB", sqlPkg, []*model.Func{
+		model.NewFunc("Begin", model.NewTypeSignature(nil, nil, []*model.Parameter{
+			model.NewParameter("", model.NewPointer(tx.Type())),
+			model.NewParameter("", errType),
+		}), ""),
+		model.NewFunc("Exec", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("query", model.NewTypeBasic("string"))},
+			model.NewParameter("args", model.NewTypeBasic("any")),
+			[]*model.Parameter{model.NewParameter("", errType)},
+		), ""),
+	})
+	txIntf := model.NewInterface("TxInterface", sqlPkg, []*model.Func{
+		model.NewFunc("Commit", model.NewTypeSignature(nil, nil, []*model.Parameter{model.NewParameter("", errType)}), ""),
+		model.NewFunc("Rollback", model.NewTypeSignature(nil, nil, nil), ""),
+	})
+
+	file := model.NewFile("", outPkg.Name(), outPkg.Path(), model.NewPackageMap(outPkg.Name(), outPkg.Path()))
+	addAdapters(file, []*model.Struct{db, tx}, []*model.Interface{dbIntf, txIntf}, outPkg)
+	file.DependenciesTidy()
+	code := file.PrintCode()
+
+	for _, want := range []string{
+		"Begin()( TxInterface, error)",
+		"// TxInterface is the interface of the methods of [sql.Tx].\ntype TxInterface interface",
+		"type SQLDB struct {\n\timpl *sql.DB\n}",
+		"func (w *SQLDB)Begin()( TxInterface, error){\nr0, r1 := w.impl.Begin()\nvar v0 TxInterface\nif r0 != nil {\nv0 = &SQLTx{impl: r0}\n}\nreturn v0, r1\n}",
+		"func (w *SQLDB)Exec(a0 string,a1 ...any) error{\nreturn w.impl.Exec(a0, a1...)\n}",
+		"func (w *SQLTx)Rollback(){\nw.impl.Rollback()\n}",
+		"func NewSQLDB(impl *sql.DB) *SQLDB{\nreturn &SQLDB{impl: impl}\n}",
+		"var _ DB = (*SQLDB)(nil)",
+		"var _ TxInterface = (*SQLTx)(nil)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("addAdapters() code = %s\nwant to contain %q", code, want)
+		}
+	}
+}
+
+func TestGetAdapterName(t *testing.T) {
+	if got := getAdapterName("sql", "DB"); got != "SQLDB" {
+		t.Errorf("getAdapterName() = %v, want SQLDB", got)
+	}
+}
@@ -0,0 +1,91 @@
+package ifacecommand
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+// methodFilter selects the methods of the generated interface.
+type methodFilter struct {
+	include *regexp.Regexp // if not nil, only the matched methods are kept
+	exclude *regexp.Regexp // if not nil, the matched methods are removed
+	methods []string       // if not empty, only the listed methods are kept
+}
+
+// newMethodFilter returns methodFilter from the flag values.
+func newMethodFilter(include, exclude, methods string) (*methodFilter, error) {
+	f := &methodFilter{}
+	var err error
+	if include != "" {
+		f.include, err = regexp.Compile(include)
+		if err != nil {
+			return nil, fmt.Errorf("-include: %w", err)
+		}
+	}
+	if exclude != "" {
+		f.exclude, err = regexp.Compile(exclude)
+		if err != nil {
+			return nil, fmt.Errorf("-exclude: %w", err)
+		}
+	}
+	for _, m := range strings.Split(methods, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			f.methods = append(f.methods, m)
+		}
+	}
+	return f, nil
+}
+
+// apply returns the interface which has only the selected methods of intf.
+// It returns error if a listed method does not exist, or the filter selects no method.
+func (f *methodFilter) apply(intf *model.Interface) (*model.Interface, error) {
+	exists := map[string]bool{}
+	for _, m := range intf.Methods() {
+		exists[m.Name()] = true
+	}
+	listed := map[string]bool{}
+	missing := []string{}
+	for _, name := range f.methods {
+		if !exists[name] {
+			missing = append(missing, name)
+		}
+		listed[name] = true
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("methods not found in the method set: %s", strings.Join(missing, ", "))
+	}
+
+	methods := []*model.Func{}
+	for _, m := range intf.Methods() {
+		if len(f.methods) > 0 && !listed[m.Name()] {
+			continue
+		}
+		if f.include != nil && !f.include.MatchString(m.Name()) {
+			continue
+		}
+		if f.exclude != nil && f.exclude.MatchString(m.Name()) {
+			continue
+		}
+		methods = append(methods, m)
+	}
+	if len(methods) == 0 && f.selects() {
+		return nil, fmt.Errorf("no methods of %s are selected", intf.Name())
+	}
+
+	var filtered *model.Interface
+	if intf.IsGeneric() {
+		filtered = model.NewGenericInterface(intf.Name(), intf.Type().Pkg(), methods, intf.TypeParams())
+	} else {
+		filtered = model.NewInterface(intf.Name(), intf.Type().Pkg(), methods)
+	}
+	filtered.SetDoc(intf.Doc())
+	return filtered, nil
+}
+
+// selects reports whether any of the flags selecting the methods is given.
+func (f *methodFilter) selects() bool {
+	return f.include != nil || f.exclude != nil || len(f.methods) > 0
+}
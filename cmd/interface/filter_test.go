@@ -0,0 +1,111 @@
+package ifacecommand
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func newFilterTestInterface(typeParams []*model.TypeParameter) *model.Interface {
+	pkg := model.NewPkgInfo("testpkg", "example.com/testpkg", "")
+	methods := []*model.Func{}
+	for _, name := range []string{"Close", "Create", "Get", "GetAll", "List", "Start"} {
+		methods = append(methods, model.NewFunc(name, model.NewTypeSignature(nil, nil, nil), ""))
+	}
+	if len(typeParams) > 0 {
+		return model.NewGenericInterface("Service", pkg, methods, typeParams)
+	}
+	return model.NewInterface("Service", pkg, methods)
+}
+
+func TestMethodFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		include   string
+		exclude   string
+		methods   string
+		expected  string
+		expectErr bool
+	}{
+		{name: "no filter", expected: "Close,Create,Get,GetAll,List,Start"},
+		{name: "include", include: "^(Get|List)", expected: "Get,GetAll,List"},
+		{name: "exclude", exclude: "^(Close|Start)$", expected: "Create,Get,GetAll,List"},
+		{name: "include and exclude", include: "^Get", exclude: "All$", expected: "Get"},
+		{name: "methods", methods: "List, Get", expected: "Get,List"},
+		{name: "methods and exclude", methods: "Get,GetAll", exclude: "All", expected: "Get"},
+		{name: "missing method", methods: "Get,Delete,Update", expectErr: true},
+		{name: "no methods selected", include: "^Delete$", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newMethodFilter(tt.include, tt.exclude, tt.methods)
+			if err != nil {
+				t.Fatalf("newMethodFilter() error = %v", err)
+			}
+			got, err := f.apply(newFilterTestInterface(nil))
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("apply() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err != nil {
+				return
+			}
+			names := []string{}
+			for _, m := range got.Methods() {
+				names = append(names, m.Name())
+			}
+			if strings.Join(names, ",") != tt.expected {
+				t.Errorf("apply() methods = %v, want %v", strings.Join(names, ","), tt.expected)
+			}
+		})
+	}
+}
+
+func TestMethodFilterMissingMethods(t *testing.T) {
+	f, _ := newMethodFilter("", "", "Get,Delete,Update")
+	_, err := f.apply(newFilterTestInterface(nil))
+	if err == nil || !strings.Contains(err.Error(), "Delete, Update") {
+		t.Errorf("apply() error = %v, want error listing the missing methods", err)
+	}
+}
+
+func TestMethodFilterEmpty(t *testing.T) {
+	empty := model.NewInterface("Order", model.NewPkgInfo("testpkg", "example.com/testpkg", ""), nil)
+
+	f, _ := newMethodFilter("", "", "")
+	got, err := f.apply(empty)
+	if err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if len(got.Methods()) != 0 {
+		t.Errorf("apply() methods = %d, want 0", len(got.Methods()))
+	}
+
+	f, _ = newMethodFilter("^Get", "", "")
+	if _, err := f.apply(empty); err == nil {
+		t.Error("apply() should fail if the filter selects no method")
+	}
+}
+
+func TestMethodFilterGeneric(t *testing.T) {
+	f, _ := newMethodFilter("^Get$", "", "")
+	got, err := f.apply(newFilterTestInterface([]*model.TypeParameter{
+		model.NewTypeParameter("T", model.ConstraintAny, 0),
+	}))
+	if err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if !got.IsGeneric() {
+		t.Error("apply() should keep the type parameters")
+	}
+}
+
+func TestNewMethodFilterInvalidRegexp(t *testing.T) {
+	if _, err := newMethodFilter("(", "", ""); err == nil {
+		t.Error("newMethodFilter() should return error for invalid -include")
+	}
+	if _, err := newMethodFilter("", "[", ""); err == nil {
+		t.Error("newMethodFilter() should return error for invalid -exclude")
+	}
+}
@@ -0,0 +1,111 @@
+package ifacecommand
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+// getDefaultImplName returns the name of the default implementation of the interface.
+func getDefaultImplName(intfName string) string {
+	return "Default" + intfName
+}
+
+// funcsIntfDoc returns the doc of the interface named name of the functions.
+func funcsIntfDoc(name string, funcs []*model.Func, outPkg *model.PkgInfo) string {
+	refs := []string{}
+	for _, fn := range funcs {
+		refs = append(refs, "["+fn.Pkg().Prefix(outPkg.Path())+fn.Name()+"]")
+	}
+	return fmt.Sprintf("%s is the interface of the functions %s.", name, strings.Join(refs, ", "))
+}
+
+// defaultImpl returns the struct implementing the interface by calling the package-level functions.
+func defaultImpl(intf *model.Interface, funcs []*model.Func, outPkg *model.PkgInfo) *model.Struct {
+	impl := model.NewStruct(getDefaultImplName(intf.Name()), outPkg)
+	impl.SetDoc(fmt.Sprintf("%s implements %s by calling the package-level functions.", impl.Name(), intf.Name()))
+
+	rcv := model.NewParameter("", impl.Type())
+	for _, m := range intf.Methods() {
+		method := model.NewMethod(rcv, m.Name(), delegateSignature(m.Type()), "")
+		if fn := findFunc(funcs, m.Name()); fn != nil {
+			method.SetDoc(fmt.Sprintf("%s calls [%s%s].", m.Name(), fn.Pkg().Prefix(outPkg.Path()), fn.Name()))
+		}
+		impl.AddMethod(method)
+	}
+	return impl
+}
+
+// setDelegateStatements sets the statements of the methods of impl calling the functions.
+func setDelegateStatements(impl *model.Struct, funcs []*model.Func, myPkgPath string, pm model.PackageMap) {
+	/*
+		return os.ReadFile(name)
+	*/
+	for _, m := range impl.Methods() {
+		fn := findFunc(funcs, m.Name())
+		if fn == nil {
+			continue
+		}
+		args := []string{}
+		for _, a := range m.Type().Args() {
+			args = append(args, a.Name())
+		}
+		if v := m.Type().Variadic(); v != nil {
+			args = append(args, v.Name()+"...")
+		}
+		stmt := fn.PrintRef(myPkgPath, pm) + "(" + strings.Join(args, ", ") + ")"
+		if len(m.Type().Results()) > 0 {
+			stmt = "return " + stmt
+		}
+		m.SetStatements(stmt)
+	}
+}
+
+// delegateSignature returns the signature whose parameters are named to be passed to the function.
+// The names of the function are kept if all of them can be used.
+func delegateSignature(org *model.TypeSignature) *model.TypeSignature {
+	params := append([]*model.Parameter{}, org.Args()...)
+	if org.Variadic() != nil {
+		params = append(params, org.Variadic())
+	}
+	named := true
+	for _, p := range params {
+		if p.Name() == "" || p.Name() == "_" {
+			named = false
+		}
+	}
+	if named {
+		return org
+	}
+	return argNamedSignature(org, org.Results())
+}
+
+// getArgName returns the name of the i-th parameter.
+func getArgName(i int) string {
+	return "a" + strconv.Itoa(i)
+}
+
+// argNamedSignature returns the signature whose parameters are named a0, a1, ... and whose results are results.
+func argNamedSignature(org *model.TypeSignature, results []*model.Parameter) *model.TypeSignature {
+	args := []*model.Parameter{}
+	for i, p := range org.Args() {
+		args = append(args, model.NewParameter(getArgName(i), p.Type()))
+	}
+	var variadic *model.Parameter
+	if org.Variadic() != nil {
+		variadic = model.NewParameter(getArgName(len(args)), org.Variadic().Type())
+	}
+	return model.NewTypeSignature(args, variadic, results)
+}
+
+// findFunc returns the function named name.
+func findFunc(funcs []*model.Func, name string) *model.Func {
+	for _, fn := range funcs {
+		if fn.Name() == name {
+			return fn
+		}
+	}
+	return nil
+}
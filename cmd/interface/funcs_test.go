@@ -0,0 +1,93 @@
+package ifacecommand
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestDelegateSignature(t *testing.T) {
+	str := model.NewTypeBasic("string")
+	tests := []struct {
+		name     string
+		sig      *model.TypeSignature
+		expected string
+	}{
+		{
+			name:     "named",
+			sig:      model.NewTypeSignature([]*model.Parameter{model.NewParameter("name", str)}, model.NewParameter("args", str), nil),
+			expected: "func(name string,args ...string)",
+		},
+		{
+			name:     "unnamed",
+			sig:      model.NewTypeSignature([]*model.Parameter{model.NewParameter("", str), model.NewParameter("", str)}, nil, nil),
+			expected: "func(a0 string,a1 string)",
+		},
+		{
+			name:     "blank",
+			sig:      model.NewTypeSignature([]*model.Parameter{model.NewParameter("name", str)}, model.NewParameter("_", str), nil),
+			expected: "func(a0 string,a1 ...string)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := delegateSignature(tt.sig).PrintType("", model.PackageMap{}); got != tt.expected {
+				t.Errorf("delegateSignature() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultImpl(t *testing.T) {
+	osPkg := model.NewPkgInfo("os", "os", "")
+	outPkg := model.NewPkgInfo("sys", "example.com/sys", "")
+	str := model.NewTypeBasic("string")
+
+	readFile := model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("name", str)},
+		nil,
+		[]*model.Parameter{model.NewParameter("", model.NewTypeBasic("error"))},
+	)
+	setenv := model.NewTypeSignature([]*model.Parameter{model.NewParameter("", str), model.NewParameter("", str)}, nil, nil)
+	funcs := []*model.Func{
+		model.NewPackageFunc(osPkg, "ReadFile", readFile),
+		model.NewPackageFunc(osPkg, "Setenv", setenv),
+	}
+	intf := model.NewInterface("FS", outPkg, []*model.Func{
+		model.NewFunc("ReadFile", readFile, ""),
+		model.NewFunc("Setenv", setenv, ""),
+	})
+
+	impl := defaultImpl(intf, funcs, outPkg)
+	pm := model.NewPackageMap(outPkg.Name(), outPkg.Path())
+	pm.Add(osPkg.Path(), *osPkg)
+	setDelegateStatements(impl, funcs, outPkg.Path(), *pm)
+
+	if impl.Name() != "DefaultFS" {
+		t.Errorf("defaultImpl() name = %v, want DefaultFS", impl.Name())
+	}
+	code := impl.PrintCode(outPkg.Path(), *pm)
+	for _, want := range []string{
+		"// ReadFile calls [os.ReadFile].\nfunc ( DefaultFS)ReadFile(name string) error{\nreturn os.ReadFile(name)\n}",
+		"func ( DefaultFS)Setenv(a0 string,a1 string){\nos.Setenv(a0, a1)\n}",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("defaultImpl() code = %q, want to contain %q", code, want)
+		}
+	}
+}
+
+func TestFuncsIntfDoc(t *testing.T) {
+	outPkg := model.NewPkgInfo("sys", "example.com/sys", "")
+	sig := model.NewTypeSignature(nil, nil, nil)
+	funcs := []*model.Func{
+		model.NewPackageFunc(model.NewPkgInfo("os", "os", ""), "ReadFile", sig),
+		model.NewPackageFunc(outPkg, "Now", sig),
+	}
+	expected := "FS is the interface of the functions [os.ReadFile], [Now]."
+	if got := funcsIntfDoc("FS", funcs, outPkg); got != expected {
+		t.Errorf("funcsIntfDoc() = %q, want %q", got, expected)
+	}
+}
@@ -1,11 +1,15 @@
 package ifacecommand
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/kmio11/codegen/cmd/internal/gen"
 	"github.com/kmio11/codegen/generator"
 	"github.com/kmio11/codegen/generator/model"
 	"github.com/kmio11/codegen/generator/parser"
@@ -20,6 +24,18 @@ type Command struct {
 	flagOutPkg      *string
 	flagSelfPkgPath *string
 	flagName        *string
+	flagUnexported  *string
+	flagOrder       *string
+	flagInclude     *string
+	flagExclude     *string
+	flagMethods     *string
+	flagReceiver    *string
+	flagConsumer    *string
+	flagFuncs       *string
+	flagAdapter     *bool
+	flagSync        *string
+	flagSplit       *bool
+	flagRoles       *string
 }
 
 // New creates a new interface command
@@ -27,11 +43,23 @@ func New() *Command {
 	c := &Command{}
 	c.fs = flag.NewFlagSet(c.Name(), flag.ContinueOnError)
 	c.flagPkg = c.fs.String("pkg", ".", "The package containing the struct to generate interface from.")
-	c.flagType = c.fs.String("type", "", "The name of the struct type to generate interface from.")
+	c.flagType = c.fs.String("type", "", "The name of the struct type to generate interface from. Comma-separated names generate the interface of their common methods.")
 	c.flagOut = c.fs.String("out", "", "Output file; defaults to stdout.")
+	c.flagSync = c.fs.String("sync", "", "Existing file declaring the interface to update in place: new methods are appended and changed signatures updated, keeping comments and order; methods no longer on the struct are reported.")
 	c.flagOutPkg = c.fs.String("outpkg", "", "Output package name; defaults to the same package specified by -pkg")
 	c.flagSelfPkgPath = c.fs.String("selfpkg", "", "The full package import path of the output package.")
+	c.flagFuncs = c.fs.String("funcs", "", "Comma-separated package-level functions to wrap behind the interface, e.g. os.ReadFile,os.Stat; -pkg is the output package, and Default<Name> calling them is generated.")
+	c.flagAdapter = c.fs.Bool("adapter", false, "Generate the adapter such as SQLDB for sql.DB forwarding to the struct, and the interfaces and adapters of the structs its methods return as pointers; the output package defaults to the current directory.")
+	c.flagConsumer = c.fs.String("consumer", "", "The package using the type; the interface has only the methods it uses, and is generated into it by default. -type is the type as referred from it, e.g. *stripe.Client; -pkg cannot be used with it.")
 	c.flagName = c.fs.String("name", "", "The name of the generated interface; defaults to <StructName>Interface")
+	c.flagInclude = c.fs.String("include", "", "Regular expression; only the matching methods are included.")
+	c.flagExclude = c.fs.String("exclude", "", "Regular expression; the matching methods are excluded.")
+	c.flagMethods = c.fs.String("methods", "", "Comma-separated list of the methods to include; every method must exist.")
+	c.flagReceiver = c.fs.String("receiver", string(parser.ReceiverBoth), "Method set to extract: value (methods of T), pointer (methods only *T has) or both (methods of *T).")
+	c.flagSplit = c.fs.Bool("split", false, "Split the methods into role interfaces (Reader, Writer, Deleter, Lifecycle) by the verb prefixes, embedded in the interface.")
+	c.flagRoles = c.fs.String("roles", "", "Role interfaces to split into, e.g. 'Reader=Get*,List*;Writer=Create*,Update*'; implies -split.")
+	c.flagOrder = c.fs.String("order", string(parser.OrderName), "Order of the methods: name (alphabetical) or source (declaration order).")
+	c.flagUnexported = c.fs.String("unexported", string(generator.UnexportedWarn), "How to handle methods referring to unexported types of other packages: warn, skip or error.")
 
 	return c
 }
@@ -60,11 +88,42 @@ Examples:
 	# Generate interface with custom name
 	%s %s -pkg ./service -type UserService -name UserServiceInterface
 
+	# Generate read-only interface
+	%s %s -pkg . -type UserService -name UserReader -include '^(Get|List)'
+
+	# Generate interface of the listed methods
+	%s %s -pkg . -type UserService -name UserWriter -methods Create,Update,Delete
+
+	# Generate interface of the methods common to the structs
+	%s %s -pkg ./store -type FileStore,S3Store,MemStore -name Store
+
+	# Generate interface wrapping package-level functions, and DefaultFS calling them
+	%s %s -funcs os.ReadFile,os.WriteFile,os.Stat -name FS
+
+	# Generate interfaces and adapters of a third-party type and the types it returns
+	%s %s -pkg database/sql -type DB -adapter -out sql_gen.go
+
+	# Generate minimal interface of the methods the consumer calls
+	%s %s -consumer ./internal/billing -type '*stripe.Client' -name StripeClient
+
+	# Split into role interfaces and the interface embedding them
+	%s %s -pkg . -type UserService -split
+	%s %s -pkg . -type UserService -roles 'UserReader=Get*,List*;UserWriter=Create*,Update*,Delete*'
+
+	# Update the hand-maintained interface in place
+	%s %s -pkg . -type UserService -name UserService -sync contracts/user.go
+
+	# Generate interface satisfied by the value
+	%s %s -pkg . -type Money -receiver value
+
+	# Keep the methods in declaration order
+	%s %s -pkg . -type UserService -order source
+
 	# Generate to different package
 	%s %s -pkg ./internal -type Handler -outpkg contracts -out ./contracts/handler.go
 
 Flags:
-`, cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name())
+`, cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name(), cmd, c.Name())
 	c.fs.PrintDefaults()
 }
 
@@ -76,9 +135,69 @@ func (c *Command) Parse(args []string) error {
 	}
 
 	// Validate required flags
-	if *c.flagType == "" {
+	if *c.flagFuncs != "" {
+		if *c.flagType != "" || *c.flagConsumer != "" {
+			return fmt.Errorf("-funcs flag cannot be used with -type or -consumer")
+		}
+		if *c.flagName == "" {
+			return fmt.Errorf("-name flag is required for -funcs")
+		}
+		for _, ref := range splitNames(*c.flagFuncs) {
+			if _, _, err := parser.SplitFuncRef(ref); err != nil {
+				return fmt.Errorf("-funcs: %w", err)
+			}
+		}
+	} else if *c.flagType == "" {
 		return fmt.Errorf("-type flag is required")
 	}
+	if strings.Contains(*c.flagType, ",") {
+		if *c.flagName == "" {
+			return fmt.Errorf("-name flag is required for multiple types")
+		}
+		if *c.flagConsumer != "" {
+			return fmt.Errorf("-consumer flag cannot be used with multiple types")
+		}
+	}
+	if *c.flagConsumer != "" {
+		// -pkg has the default value, so it is rejected only if it is given.
+		pkgSet := false
+		c.fs.Visit(func(f *flag.Flag) {
+			pkgSet = pkgSet || f.Name == "pkg"
+		})
+		if pkgSet {
+			return fmt.Errorf("-pkg flag cannot be used with -consumer; the package of -type is resolved from the consumer")
+		}
+	}
+	if *c.flagAdapter {
+		if *c.flagFuncs != "" || *c.flagConsumer != "" || strings.Contains(*c.flagType, ",") {
+			return fmt.Errorf("-adapter flag cannot be used with -funcs, -consumer or multiple types")
+		}
+		if *c.flagSplit || *c.flagRoles != "" {
+			return fmt.Errorf("-adapter flag cannot be used with -split or -roles")
+		}
+	}
+	if *c.flagSync != "" {
+		if *c.flagOut != "" || *c.flagAdapter || *c.flagSplit || *c.flagRoles != "" {
+			return fmt.Errorf("-sync flag cannot be used with -out, -adapter, -split or -roles")
+		}
+	}
+	if _, err := newMethodFilter(*c.flagInclude, *c.flagExclude, *c.flagMethods); err != nil {
+		return err
+	}
+	if *c.flagRoles != "" {
+		if _, err := parseRoles(*c.flagRoles); err != nil {
+			return fmt.Errorf("-roles: %w", err)
+		}
+	}
+	if _, err := parser.ParseReceiver(*c.flagReceiver); err != nil {
+		return fmt.Errorf("-receiver: %w", err)
+	}
+	if _, err := parser.ParseMethodOrder(*c.flagOrder); err != nil {
+		return fmt.Errorf("-order: %w", err)
+	}
+	if _, err := generator.ParseUnexportedMode(*c.flagUnexported); err != nil {
+		return fmt.Errorf("-unexported: %w", err)
+	}
 
 	return nil
 }
@@ -86,22 +205,114 @@ func (c *Command) Parse(args []string) error {
 // Execute runs the interface generation command
 func (c *Command) Execute() int {
 	// Parse the package
-	targetPkg, targetIntf, err := c.parse(*c.flagType, *c.flagPkg)
+	targetPkg, targetIntf, consumer, err := c.parse(*c.flagType, *c.flagPkg, *c.flagConsumer)
 	if err != nil {
 		log.Println(err)
 		return 1
 	}
 
+	// Keep only the methods the consumer uses, and generate the interface into it by default
+	outPkgName, selfPkgPath := *c.flagOutPkg, *c.flagSelfPkgPath
+	if consumer != nil {
+		targetIntf, err = (&methodFilter{methods: consumer.Methods}).apply(targetIntf)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		if outPkgName == "" {
+			outPkgName, selfPkgPath = consumer.Name, consumer.Path
+		}
+	}
+
+	// Generate the adapters into the package of the current directory by default
+	if *c.flagAdapter && outPkgName == "" {
+		outPkgName, selfPkgPath, err = parser.NewParser().LoadPackageName(".")
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+	}
+
+	// Sync the interface in the package of the file
+	if *c.flagSync != "" && outPkgName == "" {
+		dir := filepath.Dir(*c.flagSync)
+		if !filepath.IsAbs(dir) {
+			dir = "./" + dir
+		}
+		outPkgName, selfPkgPath, err = parser.NewParser().LoadPackageName(dir)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+	}
+
 	// Override interface name if specified
 	if *c.flagName != "" {
 		// Create new interface with custom name
 		methods := targetIntf.Methods()
 		pkgInfo := targetIntf.Type().Pkg()
-		targetIntf = model.NewInterface(*c.flagName, pkgInfo, methods)
+		doc := targetIntf.Doc()
+		if targetIntf.IsGeneric() {
+			targetIntf = model.NewGenericInterface(*c.flagName, pkgInfo, methods, targetIntf.TypeParams())
+		} else {
+			targetIntf = model.NewInterface(*c.flagName, pkgInfo, methods)
+		}
+		targetIntf.SetDoc(doc)
+	}
+
+	// Select methods
+	filter, err := newMethodFilter(*c.flagInclude, *c.flagExclude, *c.flagMethods)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	targetIntf, err = filter.apply(targetIntf)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	// Handle methods referring to unexported types of other packages
+	mode, _ := generator.ParseUnexportedMode(*c.flagUnexported)
+	_, outPkgPath := gen.OutPackage(targetPkg, *c.flagOut, outPkgName, selfPkgPath)
+	targetIntf, err = generator.FilterUnexported(targetIntf, outPkgPath, mode, log.Default())
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	// Update the existing declaration
+	if *c.flagSync != "" {
+		return c.sync(targetPkg, targetIntf, *c.flagSync, outPkgPath)
+	}
+
+	// Split the interface into role interfaces
+	roles, err := c.roles(targetIntf.Name())
+	if err != nil {
+		log.Println(err)
+		return 1
 	}
 
 	// Create output file
-	file := c.createInterfaceFile(targetPkg, targetIntf, *c.flagOut, *c.flagOutPkg, *c.flagSelfPkgPath)
+	var file *model.File
+	if *c.flagAdapter {
+		// The interfaces of the structs the methods return
+		intfs := []*model.Interface{targetIntf}
+		for _, intf := range targetPkg.Interfaces[1:] {
+			intf, err = generator.FilterUnexported(intf, outPkgPath, mode, log.Default())
+			if err != nil {
+				log.Println(err)
+				return 1
+			}
+			intfs = append(intfs, intf)
+		}
+		file = c.createAdapterFile(targetPkg, intfs, *c.flagOut, outPkgName, selfPkgPath)
+	} else {
+		// The struct can be referred without import cycle from its package and the consumer
+		receiver, _ := parser.ParseReceiver(*c.flagReceiver)
+		assert := outPkgPath == targetPkg.Path || (consumer != nil && outPkgPath == consumer.Path)
+		file = c.createInterfaceFile(targetPkg, targetIntf, *c.flagOut, outPkgName, selfPkgPath, receiver, assert, roles)
+	}
 
 	// Generate code
 	g := &generator.Generator{}
@@ -127,54 +338,235 @@ func (c *Command) Execute() int {
 	return 0
 }
 
-// parse parses the package and extracts the target struct as interface
-func (c *Command) parse(typ string, pkg string) (*model.Package, *model.Interface, error) {
+// sync updates the declaration of the interface in the file.
+func (c *Command) sync(targetPkg *model.Package, targetIntf *model.Interface, path, outPkgPath string) int {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("reading file: %s\n", err)
+		return 1
+	}
+	// the methods of the embedded interfaces are resolved with the types of the package of the file.
+	dir := filepath.Dir(path)
+	if !filepath.IsAbs(dir) {
+		dir = "./" + dir
+	}
+	p := parser.NewParser()
+	if err := p.LoadPackage(dir); err != nil {
+		log.Println(err)
+		return 1
+	}
+	embedded := embeddedMethods(p.ParsedPkg.Pkg, targetIntf.Name())
+	out, report, err := syncFile(path, src, targetIntf, embedded, targetPkg.CopyDependencies(), outPkgPath)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	for _, r := range report {
+		log.Printf("sync: %s", r)
+	}
+	if bytes.Equal(src, out) {
+		fmt.Printf("File is up to date : %s\n", path)
+		return 0
+	}
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		log.Printf("writing output: %s\n", err)
+		return 1
+	}
+	fmt.Printf("File synced successfully : %s\n", path)
+	return 0
+}
+
+// parse parses the package and extracts the target struct as interface.
+// If consumer is specified, the package of the type referred from the consumer is parsed.
+func (c *Command) parse(typ string, pkg string, consumer string) (*model.Package, *model.Interface, *parser.Consumer, error) {
 	// Create parser
+	order, _ := parser.ParseMethodOrder(*c.flagOrder)
+	receiver, _ := parser.ParseReceiver(*c.flagReceiver)
 	p := parser.NewParser(
 		parser.OptLogger(log.New(os.Stderr, "", log.LstdFlags|log.Lshortfile)),
-		parser.OptParseTarget([]string{typ}),
+		parser.OptParseTarget(splitNames(typ)),
+		parser.OptMethodOrder(order),
+		parser.OptReceiver(receiver),
 	)
 
+	// Parse the package-level functions as interface
+	if *c.flagFuncs != "" {
+		funcs, err := p.LoadFuncs(pkg, splitNames(*c.flagFuncs))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		targetPkg, err := p.ParseFuncs(*c.flagName, funcs)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return targetPkg, targetPkg.Interfaces[0], nil, nil
+	}
+
 	// Load package
-	err := p.LoadPackage(pkg)
+	var cons *parser.Consumer
+	var err error
+	if consumer != "" {
+		cons, err = p.LoadConsumer(consumer, typ)
+	} else {
+		err = p.LoadPackage(pkg)
+	}
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// Parse the package
-	targetPkg, err := p.Parse()
+	var targetPkg *model.Package
+	if len(p.Targets) > 1 {
+		// Parse the common methods of the structs
+		var conflicts []*parser.Conflict
+		targetPkg, conflicts, err = p.ParseCommon(*c.flagName)
+		for _, conflict := range conflicts {
+			log.Printf("conflict: %s", conflict)
+		}
+	} else if *c.flagAdapter {
+		// Parse the structs the methods return together
+		targetPkg, err = p.ParseWithResults()
+	} else {
+		targetPkg, err = p.Parse()
+	}
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// Check if interface was generated
 	if len(targetPkg.Interfaces) == 0 {
-		return nil, nil, fmt.Errorf("no interface generated from struct %s", typ)
+		return nil, nil, nil, fmt.Errorf("no interface generated from struct %s", typ)
 	}
 
 	targetIntf := targetPkg.Interfaces[0]
 
-	return targetPkg, targetIntf, nil
+	return targetPkg, targetIntf, cons, nil
 }
 
-// createInterfaceFile creates a file containing the generated interface
-func (c *Command) createInterfaceFile(targetPkg *model.Package, targetIntf *model.Interface, outFile, outPkgName, selfPkgPath string) *model.File {
-	// Determine output package
-	outPkgPath := selfPkgPath
-	if outPkgName != "" && outPkgPath == "" {
-		outPkgPath = outPkgName
+// roles returns the roles to split the interface named intfName into, or nil if not split.
+func (c *Command) roles(intfName string) ([]*role, error) {
+	if *c.flagRoles != "" {
+		roles, err := parseRoles(*c.flagRoles)
+		if err != nil {
+			return nil, fmt.Errorf("-roles: %w", err)
+		}
+		return roles, nil
 	}
-	if outPkgName == "" {
-		outPkgName = targetPkg.Name
-		outPkgPath = targetPkg.Path
+	if *c.flagSplit {
+		return defaultRoles(strings.TrimSuffix(intfName, "Interface")), nil
 	}
+	return nil, nil
+}
+
+// createInterfaceFile creates a file containing the generated interface.
+// If roles is not nil, the interface is split into the role interfaces embedded in it.
+// If assert is true, the assertion that the struct implements the interface is added.
+// If the package has the functions, the default implementation calling them is added.
+func (c *Command) createInterfaceFile(targetPkg *model.Package, targetIntf *model.Interface, outFile, outPkgName, selfPkgPath string, receiver parser.Receiver, assert bool, roles []*role) *model.File {
+	// Determine output package
+	outPkgName, outPkgPath := gen.OutPackage(targetPkg, outFile, outPkgName, selfPkgPath)
+	outPkg := model.NewPkgInfo(outPkgName, outPkgPath, "")
 
 	// Create file
 	file := model.NewFile(outFile, outPkgName, outPkgPath, targetPkg.CopyDependencies())
-	file.DependenciesTidy()
+
+	// Document the interface with the links to the structs or the functions
+	if len(targetPkg.Structs) > 0 {
+		targetIntf.SetDoc(structIntfDoc(targetIntf.Name(), targetPkg.Structs, outPkg))
+	} else if len(targetPkg.Functions) > 0 {
+		targetIntf.SetDoc(funcsIntfDoc(targetIntf.Name(), targetPkg.Functions, outPkg))
+	}
 
 	// Add interface to file
+	if roles != nil {
+		var roleIntfs []*model.Interface
+		roleIntfs, targetIntf = split(targetIntf, roles, outPkg, func(name string, methods []*model.Func) string {
+			return roleDoc(name, methods, targetPkg, outPkg)
+		})
+		for _, intf := range roleIntfs {
+			file.AddInterface(intf)
+		}
+	}
 	file.AddInterface(targetIntf)
 
+	// the interface is declared in the output package
+	intfType := model.NewTypeNamed(outPkg, targetIntf.Name(), targetIntf.Type().Org())
+	if targetIntf.IsGeneric() {
+		intfType = model.NewGenericTypeNamed(outPkg, targetIntf.Name(), targetIntf.Type().Org(), targetIntf.TypeParams())
+	}
+
+	// Assert that the struct implements the interface.
+	if assert {
+		pointer := receiver != parser.ReceiverValue
+		for _, st := range targetPkg.Structs {
+			file.AddAssertion(model.NewAssertion(intfType, st.Type(), pointer))
+		}
+	}
+
+	// Implement the interface by calling the functions.
+	var impl *model.Struct
+	if len(targetPkg.Functions) > 0 {
+		impl = defaultImpl(targetIntf, targetPkg.Functions, outPkg)
+		file.AddStruct(impl)
+		file.AddAssertion(model.NewAssertion(intfType, impl.Type(), false))
+		for _, m := range targetIntf.Methods() {
+			if fn := findFunc(targetPkg.Functions, m.Name()); fn != nil {
+				file.AddImport(fn.Pkg())
+			}
+		}
+	}
+	file.DependenciesTidy()
+
+	if impl != nil {
+		setDelegateStatements(impl, targetPkg.Functions, outPkgPath, *file.Dependencies())
+	}
+
 	return file
 }
+
+// intfDoc returns the doc of the interface named name of the methods of structs.
+func intfDoc(name string, structs []*model.Struct, outPkg *model.PkgInfo) string {
+	refs := []string{}
+	for _, st := range structs {
+		refs = append(refs, "["+st.Type().Pkg().Prefix(outPkg.Path())+st.Name()+"]")
+	}
+	if len(refs) > 1 {
+		return fmt.Sprintf("%s is the interface of the methods common to %s.", name, strings.Join(refs, ", "))
+	}
+	return fmt.Sprintf("%s is the interface of the methods of %s.", name, refs[0])
+}
+
+// structIntfDoc returns intfDoc followed by the doc of the struct, if the interface is of one struct.
+func structIntfDoc(name string, structs []*model.Struct, outPkg *model.PkgInfo) string {
+	doc := intfDoc(name, structs, outPkg)
+	if len(structs) == 1 && structs[0].Doc() != "" {
+		doc += "\n\n" + structs[0].Doc()
+	}
+	return doc
+}
+
+// roleDoc returns the doc of the role interface named name having the methods of the structs or the functions of pkg.
+func roleDoc(name string, methods []*model.Func, pkg *model.Package, outPkg *model.PkgInfo) string {
+	if len(pkg.Structs) > 0 {
+		return intfDoc(name, pkg.Structs, outPkg)
+	}
+	funcs := []*model.Func{}
+	for _, m := range methods {
+		if fn := findFunc(pkg.Functions, m.Name()); fn != nil {
+			funcs = append(funcs, fn)
+		}
+	}
+	if len(funcs) == 0 {
+		return ""
+	}
+	return funcsIntfDoc(name, funcs, outPkg)
+}
+
+// splitNames splits comma-separated names such as types and functions
+func splitNames(s string) []string {
+	names := []string{}
+	for _, name := range strings.Split(s, ",") {
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names
+}
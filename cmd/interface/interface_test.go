@@ -1,7 +1,10 @@
 package ifacecommand
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
 )
 
 // TestNew tests interface command creation
@@ -38,6 +41,101 @@ func TestParse(t *testing.T) {
 	if err2 == nil {
 		t.Error("Parse() should fail when -type flag is missing")
 	}
+
+	// Test invalid regular expression (should fail)
+	cmd5 := New()
+	if err := cmd5.Parse([]string{"-type", "TestStruct", "-include", "("}); err == nil {
+		t.Error("Parse() should fail when -include is invalid")
+	}
+
+	// Test invalid receiver (should fail)
+	cmd6 := New()
+	if err := cmd6.Parse([]string{"-type", "TestStruct", "-receiver", "ref"}); err == nil {
+		t.Error("Parse() should fail when -receiver is invalid")
+	}
+
+	// Test multiple types without name (should fail)
+	cmd7 := New()
+	if err := cmd7.Parse([]string{"-type", "FileStore,S3Store"}); err == nil {
+		t.Error("Parse() should fail when -name is missing for multiple types")
+	}
+
+	// Test multiple types with name
+	cmd8 := New()
+	if err := cmd8.Parse([]string{"-type", "FileStore,S3Store", "-name", "Store"}); err != nil {
+		t.Errorf("Parse() failed for multiple types: %v", err)
+	}
+
+	// Test invalid roles (should fail)
+	cmd9 := New()
+	if err := cmd9.Parse([]string{"-type", "TestStruct", "-roles", "Reader=Get[*"}); err == nil {
+		t.Error("Parse() should fail when -roles is invalid")
+	}
+
+	// Test functions
+	cmd10 := New()
+	if err := cmd10.Parse([]string{"-funcs", "os.ReadFile, os.Stat", "-name", "FS"}); err != nil {
+		t.Errorf("Parse() failed for -funcs: %v", err)
+	}
+
+	// Test functions without name (should fail)
+	cmd11 := New()
+	if err := cmd11.Parse([]string{"-funcs", "os.ReadFile"}); err == nil {
+		t.Error("Parse() should fail when -name is missing for -funcs")
+	}
+
+	// Test functions with type (should fail)
+	cmd12 := New()
+	if err := cmd12.Parse([]string{"-funcs", "os.ReadFile", "-name", "FS", "-type", "TestStruct"}); err == nil {
+		t.Error("Parse() should fail when -funcs is used with -type")
+	}
+
+	// Test invalid function (should fail)
+	cmd13 := New()
+	if err := cmd13.Parse([]string{"-funcs", "ReadFile", "-name", "FS"}); err == nil {
+		t.Error("Parse() should fail when -funcs is invalid")
+	}
+
+	// Test adapter
+	cmd14 := New()
+	if err := cmd14.Parse([]string{"-pkg", "database/sql", "-type", "DB", "-adapter"}); err != nil {
+		t.Errorf("Parse() failed for -adapter: %v", err)
+	}
+
+	// Test adapter with split (should fail)
+	cmd15 := New()
+	if err := cmd15.Parse([]string{"-type", "DB", "-adapter", "-split"}); err == nil {
+		t.Error("Parse() should fail when -adapter is used with -split")
+	}
+
+	// Test sync with out (should fail)
+	cmd16 := New()
+	if err := cmd16.Parse([]string{"-type", "TestStruct", "-sync", "test.go", "-out", "test.go"}); err == nil {
+		t.Error("Parse() should fail when -sync is used with -out")
+	}
+
+	// Test consumer with pkg (should fail)
+	cmd17 := New()
+	if err := cmd17.Parse([]string{"-consumer", "./billing", "-type", "*stripe.Client", "-pkg", "."}); err == nil {
+		t.Error("Parse() should fail when -consumer is used with -pkg")
+	}
+	cmd18 := New()
+	if err := cmd18.Parse([]string{"-consumer", "./billing", "-type", "*stripe.Client"}); err != nil {
+		t.Errorf("Parse() failed for -consumer: %v", err)
+	}
+
+	// Test invalid method order (should fail)
+	cmd4 := New()
+	if err := cmd4.Parse([]string{"-type", "TestStruct", "-order", "random"}); err == nil {
+		t.Error("Parse() should fail when -order is invalid")
+	}
+
+	// Test invalid unexported mode (should fail)
+	cmd3 := New()
+	args3 := []string{"-type", "TestStruct", "-unexported", "ignore"}
+	if err := cmd3.Parse(args3); err == nil {
+		t.Error("Parse() should fail when -unexported is invalid")
+	}
 }
 
 // TestExecute tests command execution with error handling
@@ -50,3 +148,59 @@ func TestExecute(t *testing.T) {
 		t.Error("Execute() should return non-zero exit code when no valid target is specified")
 	}
 }
+
+func TestSplitNames(t *testing.T) {
+	got := splitNames("FileStore, S3Store ,MemStore")
+	expected := []string{"FileStore", "S3Store", "MemStore"}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("splitNames() = %v, want %v", got, expected)
+	}
+}
+
+func TestIntfDoc(t *testing.T) {
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	fileStore := model.NewStruct("FileStore", appPkg)
+	memStore := model.NewStruct("MemStore", appPkg)
+
+	tests := []struct {
+		name    string
+		structs []*model.Struct
+		outPkg  *model.PkgInfo
+		want    string
+	}{
+		{"same package", []*model.Struct{fileStore}, appPkg, "FileStoreInterface is the interface of the methods of [FileStore]."},
+		{"other package", []*model.Struct{fileStore}, model.NewPkgInfo("store", "example.com/store", ""), "FileStoreInterface is the interface of the methods of [app.FileStore]."},
+		{"common", []*model.Struct{fileStore, memStore}, appPkg, "FileStoreInterface is the interface of the methods common to [FileStore], [MemStore]."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := intfDoc("FileStoreInterface", tt.structs, tt.outPkg); got != tt.want {
+				t.Errorf("intfDoc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructIntfDoc(t *testing.T) {
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	fileStore := model.NewStruct("FileStore", appPkg)
+	fileStore.SetDoc("FileStore stores the values in files.\n")
+	memStore := model.NewStruct("MemStore", appPkg)
+
+	tests := []struct {
+		name    string
+		structs []*model.Struct
+		want    string
+	}{
+		{"documented", []*model.Struct{fileStore}, "StoreInterface is the interface of the methods of [FileStore].\n\nFileStore stores the values in files.\n"},
+		{"undocumented", []*model.Struct{memStore}, "StoreInterface is the interface of the methods of [MemStore]."},
+		{"common", []*model.Struct{fileStore, memStore}, "StoreInterface is the interface of the methods common to [FileStore], [MemStore]."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := structIntfDoc("StoreInterface", tt.structs, appPkg); got != tt.want {
+				t.Errorf("structIntfDoc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
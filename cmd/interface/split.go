@@ -0,0 +1,144 @@
+package ifacecommand
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+// role is a group of methods split into an interface.
+type role struct {
+	name  string
+	match func(method string) bool
+}
+
+// parseRoles parses the mapping of role names and method patterns,
+// such as "Reader=Get*,List*;Writer=Create*,Update*,Delete*".
+// The patterns are matched by path.Match.
+func parseRoles(s string) ([]*role, error) {
+	roles := []*role{}
+	for _, def := range strings.Split(s, ";") {
+		if strings.TrimSpace(def) == "" {
+			continue
+		}
+		name, pats, ok := strings.Cut(def, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("invalid role %q: must be Name=Pattern,Pattern", def)
+		}
+		patterns := []string{}
+		for _, pat := range strings.Split(pats, ",") {
+			pat = strings.TrimSpace(pat)
+			if _, err := path.Match(pat, ""); err != nil || pat == "" {
+				return nil, fmt.Errorf("invalid pattern %q of role %s", pat, name)
+			}
+			patterns = append(patterns, pat)
+		}
+		roles = append(roles, &role{
+			name: name,
+			match: func(method string) bool {
+				for _, pat := range patterns {
+					if ok, _ := path.Match(pat, method); ok {
+						return true
+					}
+				}
+				return false
+			},
+		})
+	}
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("no roles are defined")
+	}
+	return roles, nil
+}
+
+// verbRoles is the roles of methods grouped by the verb prefix.
+var verbRoles = []struct {
+	name  string
+	verbs []string
+}{
+	{"Reader", []string{"Get", "List", "Find", "Fetch", "Load", "Read", "Search", "Query", "Count", "Exists", "Has", "Is"}},
+	{"Writer", []string{"Create", "Add", "Insert", "Save", "Put", "Set", "Update", "Upsert", "Write", "Store"}},
+	{"Deleter", []string{"Delete", "Remove", "Clear", "Purge"}},
+	{"Lifecycle", []string{"Open", "Close", "Start", "Stop", "Run", "Shutdown", "Init"}},
+}
+
+// defaultRoles returns the roles grouping methods by the verb prefix.
+// The names of the roles are prefixed with base, such as "UserServiceReader".
+func defaultRoles(base string) []*role {
+	roles := []*role{}
+	for _, vr := range verbRoles {
+		verbs := vr.verbs
+		roles = append(roles, &role{
+			name: base + vr.name,
+			match: func(method string) bool {
+				for _, verb := range verbs {
+					if hasVerb(method, verb) {
+						return true
+					}
+				}
+				return false
+			},
+		})
+	}
+	return roles
+}
+
+// hasVerb reports whether the method name starts with the word verb.
+// e.g. "GetUser" and "Get" have "Get", but "Getaway" does not.
+func hasVerb(method, verb string) bool {
+	if !strings.HasPrefix(method, verb) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(method[len(verb):])
+	return r == utf8.RuneError || !unicode.IsLower(r)
+}
+
+// split returns the role interfaces, and the composite interface
+// embedding them all and having the methods matching no roles.
+// The methods match the first matching role. The roles without methods are omitted.
+// doc returns the doc of the role interface named name having the methods.
+func split(intf *model.Interface, roles []*role, outPkg *model.PkgInfo, doc func(name string, methods []*model.Func) string) ([]*model.Interface, *model.Interface) {
+	newInterface := func(name string, methods []*model.Func, embeddeds ...*model.TypeNamed) *model.Interface {
+		if intf.IsGeneric() {
+			return model.NewGenericInterface(name, outPkg, methods, intf.TypeParams(), embeddeds...)
+		}
+		return model.NewInterface(name, outPkg, methods, embeddeds...)
+	}
+
+	grouped := make([][]*model.Func, len(roles))
+	rest := []*model.Func{}
+	for _, m := range intf.Methods() {
+		matched := false
+		for i, r := range roles {
+			if r.match(m.Name()) {
+				grouped[i] = append(grouped[i], m)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			rest = append(rest, m)
+		}
+	}
+
+	roleIntfs := []*model.Interface{}
+	embeddeds := []*model.TypeNamed{}
+	for i, r := range roles {
+		if len(grouped[i]) == 0 {
+			continue
+		}
+		ri := newInterface(r.name, grouped[i])
+		ri.SetDoc(doc(r.name, grouped[i]))
+		roleIntfs = append(roleIntfs, ri)
+		embeddeds = append(embeddeds, ri.Type())
+	}
+	composite := newInterface(intf.Name(), rest, embeddeds...)
+	composite.SetDoc(intf.Doc())
+	return roleIntfs, composite
+}
@@ -0,0 +1,143 @@
+package ifacecommand
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestParseRoles(t *testing.T) {
+	tests := []struct {
+		name      string
+		roles     string
+		method    string
+		expected  string
+		expectErr bool
+	}{
+		{name: "first role", roles: "Reader=Get*,List*;Writer=Create*", method: "GetUser", expected: "Reader"},
+		{name: "second pattern", roles: "Reader=Get*,List*;Writer=Create*", method: "ListUsers", expected: "Reader"},
+		{name: "second role", roles: "Reader=Get*, List*; Writer=Create*", method: "CreateUser", expected: "Writer"},
+		{name: "first matching role", roles: "Reader=Get*;All=*", method: "GetUser", expected: "Reader"},
+		{name: "no match", roles: "Reader=Get*", method: "Close", expected: ""},
+		{name: "trailing separator", roles: "Reader=Get*;", method: "Get", expected: "Reader"},
+		{name: "missing patterns", roles: "Reader", expectErr: true},
+		{name: "empty pattern", roles: "Reader=Get*,", expectErr: true},
+		{name: "invalid name", roles: "My Reader=Get*", expectErr: true},
+		{name: "invalid pattern", roles: "Reader=Get[*", expectErr: true},
+		{name: "no roles", roles: ";", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles, err := parseRoles(tt.roles)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("parseRoles() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err != nil {
+				return
+			}
+			got := ""
+			for _, r := range roles {
+				if r.match(tt.method) {
+					got = r.name
+					break
+				}
+			}
+			if got != tt.expected {
+				t.Errorf("role of %s = %q, want %q", tt.method, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasVerb(t *testing.T) {
+	tests := []struct {
+		method   string
+		verb     string
+		expected bool
+	}{
+		{"Get", "Get", true},
+		{"GetUser", "Get", true},
+		{"Get2", "Get", true},
+		{"Getaway", "Get", false},
+		{"Settle", "Set", false},
+		{"Reset", "Set", false},
+	}
+
+	for _, tt := range tests {
+		if got := hasVerb(tt.method, tt.verb); got != tt.expected {
+			t.Errorf("hasVerb(%q, %q) = %v, want %v", tt.method, tt.verb, got, tt.expected)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	outPkg := model.NewPkgInfo("contracts", "example.com/contracts", "")
+
+	noDoc := func(string, []*model.Func) string { return "" }
+
+	t.Run("default roles", func(t *testing.T) {
+		roleIntfs, composite := split(newFilterTestInterface(nil), defaultRoles("Service"), outPkg, func(name string, methods []*model.Func) string {
+			return name + " has " + methods[0].Name() + "."
+		})
+
+		got := []string{}
+		for _, intf := range roleIntfs {
+			names := []string{}
+			for _, m := range intf.Methods() {
+				names = append(names, m.Name())
+			}
+			got = append(got, intf.Name()+":"+strings.Join(names, ","))
+			if expected := intf.Name() + " has " + names[0] + "."; intf.Doc() != expected {
+				t.Errorf("%s.Doc() = %q, want %q", intf.Name(), intf.Doc(), expected)
+			}
+		}
+		expected := "ServiceReader:Get,GetAll,List ServiceWriter:Create ServiceLifecycle:Close,Start"
+		if strings.Join(got, " ") != expected {
+			t.Errorf("split() roles = %v, want %v", strings.Join(got, " "), expected)
+		}
+
+		code := composite.PrintCode(outPkg.Path(), model.PackageMap{})
+		expectedCode := "type Service interface{ServiceReader;ServiceWriter;ServiceLifecycle}"
+		if code != expectedCode {
+			t.Errorf("composite PrintCode() = %v, want %v", code, expectedCode)
+		}
+		if len(composite.Methods()) != 6 {
+			t.Errorf("composite has %d methods, want 6", len(composite.Methods()))
+		}
+	})
+
+	t.Run("unmatched methods", func(t *testing.T) {
+		roles, err := parseRoles("Reader=Get*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		roleIntfs, composite := split(newFilterTestInterface(nil), roles, outPkg, noDoc)
+		if len(roleIntfs) != 1 || roleIntfs[0].Name() != "Reader" {
+			t.Fatalf("split() roles = %v, want [Reader]", roleIntfs)
+		}
+		names := []string{}
+		for _, m := range composite.Type().Org().(*model.TypeInterface).ExplicitMethods() {
+			names = append(names, m.Name())
+		}
+		if strings.Join(names, ",") != "Close,Create,List,Start" {
+			t.Errorf("composite explicit methods = %v, want Close,Create,List,Start", strings.Join(names, ","))
+		}
+	})
+
+	t.Run("generic", func(t *testing.T) {
+		typeParams := []*model.TypeParameter{model.NewTypeParameter("T", model.ConstraintAny, 0)}
+		roleIntfs, composite := split(newFilterTestInterface(typeParams), defaultRoles("Service"), outPkg, noDoc)
+		for _, intf := range roleIntfs {
+			if !intf.IsGeneric() {
+				t.Errorf("%s is not generic", intf.Name())
+			}
+		}
+		code := composite.PrintCode(outPkg.Path(), model.PackageMap{})
+		expectedCode := "type Service[T any] interface{ServiceReader[T];ServiceWriter[T];ServiceLifecycle[T]}"
+		if code != expectedCode {
+			t.Errorf("composite PrintCode() = %v, want %v", code, expectedCode)
+		}
+	})
+}
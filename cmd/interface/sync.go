@@ -0,0 +1,220 @@
+package ifacecommand
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	goparser "go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kmio11/codegen/generator/model"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// syncFile updates the declaration of the interface in src to have the methods of intf.
+// The new methods are appended, and the methods whose signatures are changed are updated,
+// keeping the comments and the order of the others. The methods which intf does not have are kept,
+// and reported with the other changes.
+// The methods in embedded, which the embedded interfaces provide, are left to them.
+// deps is the packages the methods may refer to, and myPkgPath is the import path of the file.
+func syncFile(filename string, src []byte, intf *model.Interface, embedded map[string]bool, deps *model.PackageMap, myPkgPath string) ([]byte, []string, error) {
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, filename, src, goparser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	it := findInterface(f, intf.Name())
+	if it == nil {
+		return nil, nil, fmt.Errorf("interface %s not found in %s", intf.Name(), filename)
+	}
+
+	// the types are printed with the imports of the file
+	file := model.NewFile(filename, f.Name.Name, myPkgPath, deps)
+	for _, spec := range f.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		name := path.Base(p)
+		if pkg := deps.Get(p); pkg != nil {
+			name = pkg.Name()
+		}
+		alias := ""
+		if spec.Name != nil {
+			alias = spec.Name.Name
+		}
+		deps.Add(p, *model.NewPkgInfo(name, p, alias))
+	}
+	// only the packages of the methods are required, not of the interface itself.
+	for _, m := range intf.Methods() {
+		file.AddFunc(m)
+	}
+	pm := file.DependenciesTidy()
+
+	existing := map[string]*ast.Field{}
+	for _, field := range it.Methods.List {
+		for _, name := range field.Names {
+			existing[name.Name] = field
+		}
+	}
+
+	type edit struct {
+		pos, end int
+		text     string
+	}
+	edits := []edit{}
+	report := []string{}
+	methods := map[string]bool{}
+	added := ""
+	for _, m := range intf.Methods() {
+		methods[m.Name()] = true
+		if embedded[m.Name()] {
+			continue
+		}
+		def := m.PrintDef(myPkgPath, *pm)
+		field, ok := existing[m.Name()]
+		if !ok {
+			added += m.PrintDoc() + def + "\n"
+			report = append(report, "added "+m.Name())
+			continue
+		}
+
+		// compare the types ignoring the parameter names.
+		ft, ok := field.Type.(*ast.FuncType)
+		if !ok {
+			continue
+		}
+		generated, err := parseMethodType(def)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", m.Name(), err)
+		}
+		if signatureKey(fset, ft) == signatureKey(token.NewFileSet(), generated) {
+			continue
+		}
+		edits = append(edits, edit{
+			pos:  fset.Position(ft.Pos()).Offset,
+			end:  fset.Position(ft.End()).Offset,
+			text: strings.TrimPrefix(def, m.Name()),
+		})
+		report = append(report, "updated "+m.Name())
+	}
+	for _, field := range it.Methods.List {
+		for _, name := range field.Names {
+			if !methods[name.Name] {
+				report = append(report, fmt.Sprintf("%s is not a method of %s; kept", name.Name, intf.Name()))
+			}
+		}
+	}
+	if added != "" {
+		// append to the end of the interface
+		closing := fset.Position(it.Methods.Closing).Offset
+		if last := bytes.TrimRight(src[:closing], " \t"); len(last) > 0 && last[len(last)-1] != '\n' {
+			added = "\n" + added
+		}
+		edits = append(edits, edit{pos: closing, end: closing, text: added})
+	}
+	if len(edits) == 0 {
+		return src, report, nil
+	}
+
+	// apply from the end not to move the offsets
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].pos > edits[j].pos
+	})
+	out := append([]byte{}, src...)
+	for _, e := range edits {
+		out = append(out[:e.pos], append([]byte(e.text), out[e.end:]...)...)
+	}
+
+	// add the imports the methods need
+	fset = token.NewFileSet()
+	f, err = goparser.ParseFile(fset, filename, out, goparser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, pkg := range pm.Imports(myPkgPath) {
+		astutil.AddNamedImport(fset, f, pkg.Alias(), pkg.Path())
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), report, nil
+}
+
+// embeddedMethods returns the names of the methods provided by the interfaces embedded in the interface name of pkg.
+func embeddedMethods(pkg *types.Package, name string) map[string]bool {
+	methods := map[string]bool{}
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return methods
+	}
+	it, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return methods
+	}
+	for i := range it.NumEmbeddeds() {
+		embed, ok := it.EmbeddedType(i).Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		for j := range embed.NumMethods() {
+			methods[embed.Method(j).Name()] = true
+		}
+	}
+	return methods
+}
+
+// findInterface returns the interface type declared as name.
+func findInterface(f *ast.File, name string) *ast.InterfaceType {
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if it, ok := ts.Type.(*ast.InterfaceType); ok && ts.Name.Name == name {
+				return it
+			}
+		}
+	}
+	return nil
+}
+
+// parseMethodType parses the method definition such as "Get(id string) error".
+func parseMethodType(def string) (*ast.FuncType, error) {
+	expr, err := goparser.ParseExpr("interface{" + def + "}")
+	if err != nil {
+		return nil, err
+	}
+	return expr.(*ast.InterfaceType).Methods.List[0].Type.(*ast.FuncType), nil
+}
+
+// signatureKey returns the types of the parameters and the results of ft.
+func signatureKey(fset *token.FileSet, ft *ast.FuncType) string {
+	types := func(fl *ast.FieldList) string {
+		if fl == nil {
+			return ""
+		}
+		s := []string{}
+		for _, field := range fl.List {
+			var buf bytes.Buffer
+			_ = printer.Fprint(&buf, fset, field.Type)
+			// any is an alias of interface{}
+			typ := strings.ReplaceAll(buf.String(), "interface{}", "any")
+			for range max(len(field.Names), 1) {
+				s = append(s, typ)
+			}
+		}
+		return strings.Join(s, ",")
+	}
+	return "(" + types(ft.Params) + ")(" + types(ft.Results) + ")"
+}
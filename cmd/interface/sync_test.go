@@ -0,0 +1,167 @@
+package ifacecommand
+
+import (
+	"go/ast"
+	"go/importer"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestSyncFile(t *testing.T) {
+	pkg := model.NewPkgInfo("service", "example.com/service", "")
+	ctxPkg := model.NewPkgInfo("context", "context", "")
+	timePkg := model.NewPkgInfo("time", "time", "")
+	ctx := model.NewParameter("ctx", model.NewTypeNamed(ctxPkg, "Context", nil))
+	str := model.NewTypeBasic("string")
+	errResult := []*model.Parameter{model.NewParameter("", model.NewTypeBasic("error"))}
+
+	get := model.NewFunc("Get", model.NewTypeSignature(
+		[]*model.Parameter{ctx, model.NewParameter("id", str)},
+		nil,
+		[]*model.Parameter{model.NewParameter("", str), model.NewParameter("", model.NewTypeBasic("error"))},
+	), "")
+	// the parameter is added
+	del := model.NewFunc("Delete", model.NewTypeSignature(
+		[]*model.Parameter{ctx, model.NewParameter("id", str), model.NewParameter("force", model.NewTypeBasic("bool"))},
+		nil,
+		errResult,
+	), "")
+	// new method using a new import
+	expire := model.NewFunc("Expire", model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("d", model.NewTypeNamed(timePkg, "Duration", nil))},
+		nil,
+		errResult,
+	), "")
+	expire.SetDoc("Expire expires the cache.\n")
+	intf := model.NewInterface("UserService", pkg, []*model.Func{del, expire, get})
+
+	src := `package service
+
+import (
+	stdctx "context"
+)
+
+// UserService is maintained by hand.
+type UserService interface {
+	// Get returns the user.
+	Get(c stdctx.Context, userID string) (string, error) // parameter names differ
+
+	// Delete deletes the user.
+	Delete(ctx stdctx.Context, id string) error
+
+	// Ping is removed from the struct.
+	Ping() error
+}
+`
+	expected := `package service
+
+import (
+	stdctx "context"
+	"time"
+)
+
+// UserService is maintained by hand.
+type UserService interface {
+	// Get returns the user.
+	Get(c stdctx.Context, userID string) (string, error) // parameter names differ
+
+	// Delete deletes the user.
+	Delete(ctx stdctx.Context, id string, force bool) error
+
+	// Ping is removed from the struct.
+	Ping() error
+	// Expire expires the cache.
+	Expire(d time.Duration) error
+}
+`
+	deps := model.NewPackageMap(pkg.Name(), pkg.Path())
+	deps.Add(ctxPkg.Path(), *ctxPkg)
+	deps.Add(timePkg.Path(), *timePkg)
+	got, report, err := syncFile("user.go", []byte(src), intf, nil, deps, pkg.Path())
+	if err != nil {
+		t.Fatalf("syncFile() error = %v", err)
+	}
+	if string(got) != expected {
+		t.Errorf("syncFile() =\n%s\nwant\n%s", got, expected)
+	}
+	expectedReport := "updated Delete;added Expire;Ping is not a method of UserService; kept"
+	if strings.Join(report, ";") != expectedReport {
+		t.Errorf("syncFile() report = %v, want %v", strings.Join(report, ";"), expectedReport)
+	}
+
+	// up to date
+	again, report, err := syncFile("user.go", got, intf, nil, deps, pkg.Path())
+	if err != nil {
+		t.Fatalf("syncFile() error = %v", err)
+	}
+	if string(again) != string(got) {
+		t.Errorf("syncFile() changed the synced file:\n%s", again)
+	}
+	if len(report) != 1 {
+		t.Errorf("syncFile() report = %v, want only Ping", report)
+	}
+
+	// not found
+	if _, _, err := syncFile("user.go", []byte("package service\n"), intf, nil, deps, pkg.Path()); err == nil {
+		t.Error("syncFile() should fail when the interface is not declared")
+	}
+}
+
+func TestSyncFileEmbedded(t *testing.T) {
+	src := `package service
+
+import "io"
+
+type Getter interface {
+	Get(id string) (string, error)
+}
+
+// UserService is maintained by hand.
+type UserService interface {
+	io.Closer
+	Getter
+	Ping() error
+}
+`
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "user.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checked, err := (&types.Config{Importer: importer.Default()}).Check("example.com/service", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	embedded := embeddedMethods(checked, "UserService")
+	if len(embedded) != 2 || !embedded["Close"] || !embedded["Get"] {
+		t.Fatalf("embeddedMethods() = %v, want Close and Get", embedded)
+	}
+
+	pkg := model.NewPkgInfo("service", "example.com/service", "")
+	str := model.NewTypeBasic("string")
+	errResult := []*model.Parameter{model.NewParameter("", model.NewTypeBasic("error"))}
+	intf := model.NewInterface("UserService", pkg, []*model.Func{
+		model.NewFunc("Close", model.NewTypeSignature(nil, nil, errResult), ""),
+		model.NewFunc("Get", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("id", str)},
+			nil,
+			[]*model.Parameter{model.NewParameter("", str), model.NewParameter("", model.NewTypeBasic("error"))},
+		), ""),
+		model.NewFunc("Ping", model.NewTypeSignature(nil, nil, errResult), ""),
+	})
+	got, report, err := syncFile("user.go", []byte(src), intf, embedded, model.NewPackageMap(pkg.Name(), pkg.Path()), pkg.Path())
+	if err != nil {
+		t.Fatalf("syncFile() error = %v", err)
+	}
+	if string(got) != src {
+		t.Errorf("syncFile() should keep the methods of the embedded interfaces:\n%s", got)
+	}
+	if len(report) != 0 {
+		t.Errorf("syncFile() report = %v, want empty", report)
+	}
+}
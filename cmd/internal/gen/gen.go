@@ -0,0 +1,159 @@
+// Package gen provides the helpers shared by the commands generating implementations of interfaces.
+package gen
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kmio11/codegen/generator/model"
+	"github.com/kmio11/codegen/generator/parser"
+)
+
+var (
+	// ContextPkg is the package context.
+	ContextPkg = model.NewPkgInfo("context", "context", "")
+
+	// ErrorType is the predeclared type error.
+	ErrorType = model.NewTypeNamed(nil, "error", model.NewTypeInterface(nil, nil))
+	// AnyType is the predeclared type any.
+	AnyType = model.NewTypeNamed(nil, "any", model.NewTypeInterface(nil, nil))
+)
+
+// ParseInterface parses the package and extracts the target interface.
+// It fails if typ is a struct.
+func ParseInterface(typ string, pkg string) (*model.Package, *model.Interface, error) {
+	p := parser.NewParser(
+		parser.OptLogger(log.New(os.Stderr, "", log.LstdFlags|log.Lshortfile)),
+		parser.OptParseTarget([]string{typ}),
+	)
+
+	err := p.LoadPackage(pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targetPkg, err := p.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// a struct is parsed as both the interface of its methods and the struct
+	if len(targetPkg.Structs) > 0 {
+		return nil, nil, fmt.Errorf("%s is not interface", typ)
+	}
+
+	return targetPkg, targetPkg.Interfaces[0], nil
+}
+
+// OutPackage returns the name and the import path of the output package.
+// If outFile is in the external test package of targetPkg (package xxx_test), the path is targetPkg's path with "_test" suffix.
+func OutPackage(targetPkg *model.Package, outFile, outPkgName, selfPkgPath string) (name, path string) {
+	if outPkgName == "" {
+		return targetPkg.Name, targetPkg.Path
+	}
+	if selfPkgPath != "" {
+		return outPkgName, selfPkgPath
+	}
+	if isExternalTest(outFile, outPkgName) && outPkgName == targetPkg.Name+"_test" {
+		return outPkgName, targetPkg.Path + "_test"
+	}
+	return outPkgName, outPkgName
+}
+
+// isExternalTest reports whether the output is the external test package (package xxx_test).
+func isExternalTest(outFile, outPkgName string) bool {
+	return strings.HasSuffix(outFile, "_test.go") && strings.HasSuffix(outPkgName, "_test")
+}
+
+// IntfRef returns the name referring to intf from the package of myPkgPath, used in doc links such as [app.Store].
+func IntfRef(intf *model.Interface, myPkgPath string) string {
+	return intf.Type().Pkg().Prefix(myPkgPath) + intf.Name()
+}
+
+// ArgName returns the name of the i-th parameter.
+func ArgName(i int) string {
+	return "a" + strconv.Itoa(i)
+}
+
+// ArgNamedSignature returns the signature whose parameters are named a0, a1, ... and whose results are unnamed.
+func ArgNamedSignature(org *model.TypeSignature) *model.TypeSignature {
+	args := []*model.Parameter{}
+	for i, p := range org.Args() {
+		args = append(args, model.NewParameter(ArgName(i), p.Type()))
+	}
+	var variadic *model.Parameter
+	if org.Variadic() != nil {
+		variadic = model.NewParameter(ArgName(len(args)), org.Variadic().Type())
+	}
+	results := []*model.Parameter{}
+	for _, r := range org.Results() {
+		results = append(results, model.NewParameter("", r.Type()))
+	}
+	return model.NewTypeSignature(args, variadic, results)
+}
+
+// CallMethod returns the expression calling the method of recv with args.
+func CallMethod(recv string, m *model.Func, args []string) string {
+	/*
+		w.Next.Get(a0, a1...)
+	*/
+	values := []interface{}{}
+	for _, a := range args {
+		values = append(values, a)
+	}
+	return recv + "." + m.Name() + fmt.Sprintf(m.Type().PrintCallArgsFmt(), values...)
+}
+
+// HasContext returns true if the first parameter is context.Context.
+func HasContext(sig *model.TypeSignature) bool {
+	if len(sig.Args()) == 0 {
+		return false
+	}
+	t, ok := sig.Args()[0].Type().(*model.TypeNamed)
+	return ok && t.Pkg() != nil && t.Pkg().Path() == ContextPkg.Path() && t.Name() == "Context"
+}
+
+// ErrorResult returns the index of the last result if it is error, or -1.
+func ErrorResult(sig *model.TypeSignature) int {
+	n := len(sig.Results())
+	if n == 0 {
+		return -1
+	}
+	t, ok := sig.Results()[n-1].Type().(*model.TypeNamed)
+	if !ok || t.Pkg() != nil || t.Name() != "error" {
+		return -1
+	}
+	return n - 1
+}
+
+// Qualifier returns the prefix to refer to the members of pkg from myPkgPath.
+// The statements refer to packages, so it is used after the imports of the file are resolved.
+func Qualifier(pm model.PackageMap, pkg *model.PkgInfo, myPkgPath string) string {
+	if p := pm.Get(pkg.Path()); p != nil {
+		return p.Prefix(myPkgPath)
+	}
+	return pkg.Prefix(myPkgPath)
+}
+
+// CheckNames returns an error if s has a field and a method, or two methods, of the same name.
+// It happens when the interface has a method named like a field or a helper method the command adds, such as Next.
+func CheckNames(s *model.Struct) error {
+	fields := map[string]bool{}
+	for _, f := range s.Fields() {
+		fields[f.Name()] = true
+	}
+	methods := map[string]bool{}
+	for _, m := range s.Methods() {
+		if fields[m.Name()] {
+			return fmt.Errorf("%s cannot have both the field and the method named %s", s.Name(), m.Name())
+		}
+		if methods[m.Name()] {
+			return fmt.Errorf("%s cannot have two methods named %s", s.Name(), m.Name())
+		}
+		methods[m.Name()] = true
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package gen
+
+import (
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestOutPackage(t *testing.T) {
+	targetPkg := &model.Package{Name: "app", Path: "example.com/app"}
+	tests := []struct {
+		outFile, outPkgName, selfPkgPath string
+		wantName, wantPath               string
+	}{
+		{"", "", "", "app", "example.com/app"},
+		{"", "logged", "", "logged", "logged"},
+		{"", "logged", "example.com/app/logged", "logged", "example.com/app/logged"},
+		{"app_mock_test.go", "app_test", "", "app_test", "example.com/app_test"},
+		{"app_mock.go", "app_test", "", "app_test", "app_test"},
+		{"app_mock_test.go", "other_test", "", "other_test", "other_test"},
+	}
+	for _, tt := range tests {
+		name, path := OutPackage(targetPkg, tt.outFile, tt.outPkgName, tt.selfPkgPath)
+		if name != tt.wantName || path != tt.wantPath {
+			t.Errorf("OutPackage(%q, %q, %q) = %v, %v, want %v, %v", tt.outFile, tt.outPkgName, tt.selfPkgPath, name, path, tt.wantName, tt.wantPath)
+		}
+	}
+}
+
+func TestIsExternalTest(t *testing.T) {
+	tests := []struct {
+		outFile    string
+		outPkgName string
+		expected   bool
+	}{
+		{"calculator_mock_test.go", "calc_test", true},
+		{"calculator_mock_test.go", "calc", false},
+		{"calculator_mock.go", "calc_test", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.outFile+":"+tt.outPkgName, func(t *testing.T) {
+			if got := isExternalTest(tt.outFile, tt.outPkgName); got != tt.expected {
+				t.Errorf("isExternalTest(%v, %v) = %v, want %v", tt.outFile, tt.outPkgName, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSignatureHelpers(t *testing.T) {
+	ctxType := model.NewTypeNamed(ContextPkg, "Context", model.NewTypeInterface(nil, nil))
+	tests := []struct {
+		name        string
+		sig         *model.TypeSignature
+		wantContext bool
+		wantError   int
+		wantSig     string
+	}{
+		{
+			name: "context and error",
+			sig: model.NewTypeSignature(
+				[]*model.Parameter{model.NewParameter("ctx", ctxType), model.NewParameter("", model.NewTypeBasic("string"))},
+				model.NewParameter("opts", model.NewTypeBasic("int")),
+				[]*model.Parameter{model.NewParameter("n", model.NewTypeBasic("int")), model.NewParameter("err", ErrorType)},
+			),
+			wantContext: true,
+			wantError:   1,
+			wantSig:     "func(a0 context.Context,a1 string,a2 ...int)( int, error)",
+		},
+		{
+			name:        "no results",
+			sig:         model.NewTypeSignature([]*model.Parameter{model.NewParameter("_", ctxType)}, nil, nil),
+			wantContext: true,
+			wantError:   -1,
+			wantSig:     "func(a0 context.Context)",
+		},
+		{
+			name: "error not last",
+			sig: model.NewTypeSignature(nil, nil,
+				[]*model.Parameter{model.NewParameter("", ErrorType), model.NewParameter("", model.NewTypeBasic("int"))},
+			),
+			wantError: -1,
+			wantSig:   "func()( error, int)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasContext(tt.sig); got != tt.wantContext {
+				t.Errorf("HasContext() = %v, want %v", got, tt.wantContext)
+			}
+			if got := ErrorResult(tt.sig); got != tt.wantError {
+				t.Errorf("ErrorResult() = %v, want %v", got, tt.wantError)
+			}
+			if got := ArgNamedSignature(tt.sig).PrintType("example.com/app", *model.NewPackageMap("app", "example.com/app")); got != tt.wantSig {
+				t.Errorf("ArgNamedSignature() = %v, want %v", got, tt.wantSig)
+			}
+		})
+	}
+}
+
+func TestCallMethod(t *testing.T) {
+	m := model.NewFunc("Get", model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("id", model.NewTypeBasic("string"))},
+		model.NewParameter("opts", model.NewTypeBasic("int")),
+		nil,
+	), "")
+	want := "w.Next.Get(a0,a1...)"
+	if got := CallMethod("w.Next", m, []string{"a0", "a1..."}); got != want {
+		t.Errorf("CallMethod() = %v, want %v", got, want)
+	}
+}
+
+func TestQualifier(t *testing.T) {
+	pm := model.NewPackageMap("app", "example.com/app")
+	pm.Add(ContextPkg.Path(), *model.NewPkgInfo("context", "context", "ctx"))
+	if got := Qualifier(*pm, ContextPkg, "example.com/app"); got != "ctx." {
+		t.Errorf("Qualifier() = %v, want %v", got, "ctx.")
+	}
+	timePkg := model.NewPkgInfo("time", "time", "")
+	if got := Qualifier(*pm, timePkg, "example.com/app"); got != "time." {
+		t.Errorf("Qualifier() = %v, want %v", got, "time.")
+	}
+}
+
+func TestCheckNames(t *testing.T) {
+	pkg := model.NewPkgInfo("app", "example.com/app", "")
+	newStruct := func(field string, methods ...string) *model.Struct {
+		s := model.NewStruct("LoggedRows", pkg)
+		s.AddField(model.NewField(field, model.NewTypeBasic("int"), ""))
+		rcv := model.NewParameter("w", model.NewPointer(s.Type()))
+		for _, m := range methods {
+			s.AddMethod(model.NewMethod(rcv, m, model.NewTypeSignature(nil, nil, nil), ""))
+		}
+		return s
+	}
+	tests := []struct {
+		name      string
+		s         *model.Struct
+		expectErr bool
+	}{
+		{"distinct", newStruct("Next", "Scan", "Close"), false},
+		{"field and method", newStruct("Next", "Scan", "Next"), true},
+		{"two methods", newStruct("Next", "retry", "retry"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckNames(tt.s); (err != nil) != tt.expectErr {
+				t.Errorf("CheckNames() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,462 @@
+package middleware
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+// Command implements the middleware generation command
+type Command struct {
+	fs              *flag.FlagSet
+	flagPkg         *string
+	flagType        *string
+	flagOut         *string
+	flagOutPkg      *string
+	flagSelfPkgPath *string
+}
+
+// New creates a new middleware command
+func New() *Command {
+	c := &Command{}
+	c.fs = flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	c.flagPkg = c.fs.String("pkg", ".", "The package containing the interface.")
+	c.flagType = c.fs.String("type", "", "The name of the interface.")
+	c.flagOut = c.fs.String("out", "", "Output file; defaults to stdout.")
+	c.flagOutPkg = c.fs.String("outpkg", "", "Output package name; defaults to the same package specified by -pkg")
+	c.flagSelfPkgPath = c.fs.String("selfpkg", "", "The full package import path of the output package.")
+
+	return c
+}
+
+// Name returns the command name
+func (c Command) Name() string {
+	return "middleware"
+}
+
+// Description returns the command description
+func (c Command) Description() string {
+	return "generate middleware chain of interface"
+}
+
+// Usage prints usage information
+func (c Command) Usage(cmd string) {
+	fmt.Printf(`Usage:
+	%s %s -type <interface> [flags]
+
+Generate a struct which implements the interface by running every call through a chain of middleware,
+so that middleware such as auth, metrics or tracing is written once for all the methods.
+Each call is described by <interface>Invocation holding the method name and its typed arguments and results.
+
+Examples:
+	# Generate ServiceInvocation, ServiceHandler, ServiceMiddleware and ChainedService
+	%s %s -pkg . -type Service -out service_middleware_gen.go
+
+Flags:
+`, cmd, c.Name(), cmd, c.Name())
+	c.fs.PrintDefaults()
+}
+
+// Parse parses command line arguments
+func (c *Command) Parse(args []string) error {
+	err := c.fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	// Validate required flags
+	if *c.flagType == "" {
+		return fmt.Errorf("-type flag is required")
+	}
+	if *c.flagOutPkg == "" && *c.flagSelfPkgPath != "" {
+		return fmt.Errorf("-selfpkg requires -outpkg")
+	}
+
+	return nil
+}
+
+// Execute runs the middleware generation command
+func (c *Command) Execute() int {
+	// Parse the package
+	targetPkg, targetIntf, err := gen.ParseInterface(*c.flagType, *c.flagPkg)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	// the wrapper cannot implement methods referring to unexported types of other packages.
+	outPkgName, outPkgPath := gen.OutPackage(targetPkg, *c.flagOut, *c.flagOutPkg, *c.flagSelfPkgPath)
+	targetIntf, err = generator.FilterUnexported(targetIntf, outPkgPath, generator.UnexportedError, log.Default())
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	// Create output file
+	file, err := middlewareFile(targetPkg, targetIntf, *c.flagOut, model.NewPkgInfo(outPkgName, outPkgPath, ""))
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	// Generate code
+	g := &generator.Generator{}
+	src := g.
+		PrintHeader(c.Name()).
+		Printf("// Middleware for %s.%s", targetPkg.Path, targetIntf.Name()).
+		NewLine().
+		Printf("%s", file.PrintCode()).
+		Format()
+
+	// Output
+	if file.Path() == "" {
+		fmt.Println(string(src))
+	} else {
+		err := os.WriteFile(file.Path(), src, 0644)
+		if err != nil {
+			log.Printf("writing output: %s\n", err)
+			return 1
+		}
+		fmt.Printf("File created successfully : %s\n", file.Path())
+	}
+
+	return 0
+}
+
+const (
+	chainRcvName        = "w"
+	chainNextName       = "Next"
+	chainMiddlewareName = "Middleware"
+	chainHandleName     = "handle"
+	invContextName      = "Context"
+	invMethodName       = "Method"
+	invArgsName         = "Args"
+	invResultsName      = "Results"
+	invErrName          = "Err"
+)
+
+func getInvocationName(intfName string) string {
+	return intfName + "Invocation"
+}
+
+func getHandlerName(intfName string) string {
+	return intfName + "Handler"
+}
+
+func getMiddlewareName(intfName string) string {
+	return intfName + "Middleware"
+}
+
+func getChainedName(intfName string) string {
+	return "Chained" + intfName
+}
+
+// getTupleName returns the name of the struct holding the arguments or the results of the method, such as ServiceGetArgs.
+func getTupleName(intfName, methodName, suffix string) string {
+	return intfName + methodName + suffix
+}
+
+// chain is the types generated for the interface.
+type chain struct {
+	intf       *model.Interface
+	intfType   *model.TypeNamed // the interface referred from the output package
+	invocation *model.Struct
+	handler    *model.TypeDef
+	middleware *model.TypeDef
+	impl       *model.Struct
+	handle     *model.Method
+	tuples     []*tuples // tuples[i] is of the i-th method
+	outPkg     *model.PkgInfo
+}
+
+// tuples is the structs holding the arguments and the results of a method.
+type tuples struct {
+	args         *model.Struct // the arguments other than context.Context
+	results      *model.Struct // the results other than error
+	argFields    []string      // argFields[i] is the field of the i-th parameter, or "" for context.Context
+	resultFields []string      // resultFields[i] is the field of the i-th result, or "" for error
+}
+
+// middlewareFile returns the file which has the middleware chain of targetIntf.
+func middlewareFile(targetPkg *model.Package, targetIntf *model.Interface, outFile string, outPkg *model.PkgInfo) (*model.File, error) {
+	file := model.NewFile(outFile, outPkg.Name(), outPkg.Path(), targetPkg.CopyDependencies())
+	file.DependenciesTidy()
+
+	c := newChain(targetIntf, outPkg)
+	if err := gen.CheckNames(c.impl); err != nil {
+		return nil, err
+	}
+	file.AddImport(gen.ContextPkg)
+	file.AddStruct(c.invocation)
+	file.AddTypeDef(c.handler)
+	file.AddTypeDef(c.middleware)
+	for _, t := range c.tuples {
+		file.AddStruct(t.args)
+		file.AddStruct(t.results)
+	}
+	file.AddStruct(c.impl)
+	file.AddFunc(c.constructor())
+	file.AddAssertion(model.NewAssertion(c.intfType, c.impl.Type(), true))
+
+	file.DependenciesTidy()
+
+	c.setStatements(*file.Dependencies())
+	return file, nil
+}
+
+// newChain returns the chain of intf, whose methods have no statements yet.
+func newChain(intf *model.Interface, outPkg *model.PkgInfo) *chain {
+	c := &chain{
+		intf:     intf,
+		intfType: intf.Type(),
+		outPkg:   outPkg,
+	}
+	name := intf.Name()
+	ref := gen.IntfRef(intf, outPkg.Path())
+	newStruct := func(name string) *model.Struct {
+		if intf.IsGeneric() {
+			return model.NewGenericStruct(name, outPkg, intf.TypeParams())
+		}
+		return model.NewStruct(name, outPkg)
+	}
+	if intf.IsGeneric() {
+		c.intfType = model.NewGenericTypeNamed(intf.Type().Pkg(), name, intf.Type().Org(), intf.TypeParams())
+	}
+
+	// the invocation, the handler and the middleware refer to the arguments and the results as any,
+	// so that a middleware is shared by all the methods.
+	c.invocation = model.NewStruct(getInvocationName(name), outPkg)
+	c.invocation.AddField(model.NewField(invContextName, model.NewTypeNamed(gen.ContextPkg, "Context", model.NewTypeInterface(nil, nil)), ""))
+	c.invocation.AddField(model.NewField(invMethodName, model.NewTypeBasic("string"), ""))
+	c.invocation.AddField(model.NewField(invArgsName, gen.AnyType, ""))
+	c.invocation.AddField(model.NewField(invResultsName, gen.AnyType, ""))
+	c.invocation.AddField(model.NewField(invErrName, gen.ErrorType, ""))
+	c.invocation.SetDoc(fmt.Sprintf(`%s describes a call of a method of [%s] passed through [%s].
+Method is the name of the method, such as %q.
+Context is the context.Context argument of the method, or context.Background() if it takes none.
+Args and Results point to the structs of the method, such as *%s and *%s;
+the context.Context argument and the error result are not included in them.
+Err is the error result of the method, which is always nil if it returns none.
+A middleware may replace Context, Args and Results with values of the same types, and set Err.`,
+		c.invocation.Name(), ref, getMiddlewareName(name), firstMethodName(intf),
+		getTupleName(name, firstMethodName(intf), "Args"), getTupleName(name, firstMethodName(intf), "Results")))
+
+	c.handler = model.NewTypeDef(getHandlerName(name), outPkg, model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("inv", model.NewPointer(c.invocation.Type()))}, nil, nil,
+	))
+	c.handler.SetDoc(fmt.Sprintf("%s handles the invocation of a method of [%s], setting its Results and Err.", c.handler.Name(), ref))
+	c.middleware = model.NewTypeDef(getMiddlewareName(name), outPkg, model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("next", c.handler.Type())},
+		nil,
+		[]*model.Parameter{model.NewParameter("", c.handler.Type())},
+	))
+	c.middleware.SetDoc(fmt.Sprintf("%s returns the handler which handles the invocations of [%s] before, after or instead of next.", c.middleware.Name(), ref))
+
+	for _, m := range intf.Methods() {
+		sig := m.Type()
+		t := &tuples{
+			args:    newStruct(getTupleName(name, m.Name(), "Args")),
+			results: newStruct(getTupleName(name, m.Name(), "Results")),
+		}
+		t.args.SetDoc(fmt.Sprintf("%s holds the arguments of [%s.%s].", t.args.Name(), ref, m.Name()))
+		t.results.SetDoc(fmt.Sprintf("%s holds the results of [%s.%s].", t.results.Name(), ref, m.Name()))
+
+		params := append([]*model.Parameter{}, sig.Args()...)
+		if sig.Variadic() != nil {
+			params = append(params, model.NewParameter(sig.Variadic().Name(), model.NewTypeArray(-1, sig.Variadic().Type())))
+		}
+		seen := map[string]bool{}
+		for i, p := range params {
+			if i == 0 && gen.HasContext(sig) {
+				t.argFields = append(t.argFields, "")
+				continue
+			}
+			field := fieldName(p.Name(), "A", i, seen)
+			t.args.AddField(model.NewField(field, p.Type(), ""))
+			t.argFields = append(t.argFields, field)
+		}
+		errIndex := gen.ErrorResult(sig)
+		seen = map[string]bool{}
+		for i, r := range sig.Results() {
+			if i == errIndex {
+				t.resultFields = append(t.resultFields, "")
+				continue
+			}
+			field := fieldName(r.Name(), "R", i, seen)
+			t.results.AddField(model.NewField(field, r.Type(), ""))
+			t.resultFields = append(t.resultFields, field)
+		}
+		c.tuples = append(c.tuples, t)
+	}
+
+	c.impl = newStruct(getChainedName(name))
+	c.impl.AddField(model.NewField(chainNextName, c.intfType, ""))
+	c.impl.AddField(model.NewField(chainMiddlewareName, model.NewTypeArray(-1, c.middleware.Type()), ""))
+	c.impl.SetDoc(fmt.Sprintf(`%s wraps [%s] and runs every call through Middleware before calling Next.
+The first middleware is the outermost one, and Next is called by the innermost handler
+with the Context and the Args of the invocation.`, c.impl.Name(), ref))
+	rcv := model.NewParameter(chainRcvName, model.NewPointer(c.impl.Type()))
+	for _, m := range intf.Methods() {
+		method := model.NewMethod(rcv, m.Name(), gen.ArgNamedSignature(m.Type()), "")
+		method.SetDoc(fmt.Sprintf("%s calls [%s.%s] of Next through Middleware.", m.Name(), ref, m.Name()))
+		c.impl.AddMethod(method)
+	}
+	c.handle = model.NewMethod(rcv, chainHandleName, model.NewTypeSignature(
+		[]*model.Parameter{
+			model.NewParameter("inv", model.NewPointer(c.invocation.Type())),
+			model.NewParameter("h", c.handler.Type()),
+		},
+		nil, nil,
+	), "")
+	c.handle.SetDoc(chainHandleName + " runs inv through Middleware and then h.")
+	c.impl.AddMethod(c.handle)
+
+	return c
+}
+
+// constructor returns New<Chained> which takes Next and Middleware.
+func (c *chain) constructor() *model.Func {
+	/*
+		return &ChainedService{Next: next, Middleware: middleware}
+	*/
+	sig := model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("next", c.intfType)},
+		model.NewParameter("middleware", c.middleware.Type()),
+		[]*model.Parameter{model.NewParameter("", model.NewPointer(c.impl.Type()))},
+	)
+	name := "New" + c.impl.Name()
+	stmt := "return &" + c.impl.Name() + typeArgs(c.impl) + "{" + chainNextName + ": next, " + chainMiddlewareName + ": middleware}"
+	fn := model.NewFunc(name, sig, stmt)
+	if c.impl.IsGeneric() {
+		fn = model.NewGenericFunc(name, sig, stmt, c.impl.TypeParams())
+	}
+	fn.SetDoc(fmt.Sprintf("%s returns %s which calls next through middleware.", name, c.impl.Name()))
+	return fn
+}
+
+// setStatements sets the statements of the methods of the chain.
+func (c *chain) setStatements(pm model.PackageMap) {
+	contextQ := gen.Qualifier(pm, gen.ContextPkg, c.outPkg.Path())
+	w := chainRcvName
+
+	/*
+		inv := &ServiceInvocation{Context: a0, Method: "Get", Args: &ServiceGetArgs{ID: a1}, Results: &ServiceGetResults{}}
+		w.handle(inv, func(inv *ServiceInvocation) {
+			args := inv.Args.(*ServiceGetArgs)
+			results := inv.Results.(*ServiceGetResults)
+			results.R0, inv.Err = w.Next.Get(inv.Context, args.ID)
+		})
+		results := inv.Results.(*ServiceGetResults)
+		return results.R0, inv.Err
+	*/
+	methods := c.impl.Methods()
+	for i, m := range c.intf.Methods() {
+		sig := m.Type()
+		t := c.tuples[i]
+		argsType := t.args.Type().PrintType(c.outPkg.Path(), pm)
+		resultsType := t.results.Type().PrintType(c.outPkg.Path(), pm)
+
+		ctx := contextQ + "Background()"
+		inits := []string{}
+		callArgs := []string{}
+		for j, field := range t.argFields {
+			if field == "" {
+				ctx = gen.ArgName(j)
+				callArgs = append(callArgs, "inv."+invContextName)
+				continue
+			}
+			inits = append(inits, field+": "+gen.ArgName(j))
+			callArgs = append(callArgs, "args."+field)
+		}
+		if sig.Variadic() != nil {
+			callArgs[len(callArgs)-1] += "..."
+		}
+		results := []string{}
+		for _, field := range t.resultFields {
+			if field == "" {
+				results = append(results, "inv."+invErrName)
+				continue
+			}
+			results = append(results, "results."+field)
+		}
+
+		s := fmt.Sprintf("inv := &%s{%s: %s, %s: %q, %s: &%s{%s}, %s: &%s{}}\n",
+			c.invocation.Name(), invContextName, ctx, invMethodName, m.Name(),
+			invArgsName, argsType, strings.Join(inits, ", "), invResultsName, resultsType)
+		s += w + "." + chainHandleName + "(inv, func(inv *" + c.invocation.Name() + ") {\n"
+		if len(t.args.Fields()) > 0 {
+			s += "args := inv." + invArgsName + ".(*" + argsType + ")\n"
+		}
+		if len(t.results.Fields()) > 0 {
+			s += "results := inv." + invResultsName + ".(*" + resultsType + ")\n"
+		}
+		if len(results) > 0 {
+			s += strings.Join(results, ", ") + " = "
+		}
+		s += gen.CallMethod(chainRcvName+"."+chainNextName, m, callArgs) + "\n"
+		s += "})"
+		if len(results) > 0 {
+			if len(t.results.Fields()) > 0 {
+				s += "\nresults := inv." + invResultsName + ".(*" + resultsType + ")"
+			}
+			s += "\nreturn " + strings.Join(results, ", ")
+		}
+		methods[i].SetStatements(s)
+	}
+
+	/*
+		for i := len(w.Middleware) - 1; i >= 0; i-- {
+			h = w.Middleware[i](h)
+		}
+		h(inv)
+	*/
+	s := "for i := len(" + w + "." + chainMiddlewareName + ") - 1; i >= 0; i-- {\n"
+	s += "h = " + w + "." + chainMiddlewareName + "[i](h)\n"
+	s += "}\n"
+	s += "h(inv)"
+	c.handle.SetStatements(s)
+}
+
+// firstMethodName returns the name of the first method used in the doc, or "Method" if there are no methods.
+func firstMethodName(intf *model.Interface) string {
+	if len(intf.Methods()) == 0 {
+		return "Method"
+	}
+	return intf.Methods()[0].Name()
+}
+
+// fieldName returns the exported name of the field holding the parameter or the result named name,
+// or prefix<i> if it is unnamed or conflicts with the other fields.
+func fieldName(name, prefix string, i int, seen map[string]bool) string {
+	field := prefix + strconv.Itoa(i)
+	if name != "" && name != "_" {
+		r, size := utf8.DecodeRuneInString(name)
+		field = string(unicode.ToUpper(r)) + name[size:]
+	}
+	if seen[field] {
+		field = prefix + strconv.Itoa(i)
+	}
+	seen[field] = true
+	return field
+}
+
+// typeArgs returns the type arguments instantiating s with its own type parameters, such as [K, V].
+func typeArgs(s *model.Struct) string {
+	if !s.IsGeneric() {
+		return ""
+	}
+	names := []string{}
+	for _, tp := range s.TypeParams() {
+		names = append(names, tp.Name())
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestCommand(t *testing.T) {
+	cmd := New()
+	if cmd == nil {
+		t.Fatal("New() returned nil")
+	}
+
+	if cmd.Name() != "middleware" {
+		t.Errorf("Name() = %v, want %v", cmd.Name(), "middleware")
+	}
+
+	if cmd.Description() != "generate middleware chain of interface" {
+		t.Errorf("Description() = %v, want %v", cmd.Description(), "generate middleware chain of interface")
+	}
+}
+
+func TestCommandParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		expectErr bool
+	}{
+		{
+			name:      "valid args",
+			args:      []string{"-pkg", ".", "-type", "Service"},
+			expectErr: false,
+		},
+		{
+			name:      "missing type",
+			args:      []string{"-pkg", "."},
+			expectErr: true,
+		},
+		{
+			name:      "selfpkg without outpkg",
+			args:      []string{"-type", "Service", "-selfpkg", "example.com/app"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := New()
+			err := cmd.Parse(tt.args)
+
+			if tt.expectErr && err == nil {
+				t.Error("Parse() should return error for invalid args")
+			}
+
+			if !tt.expectErr && err != nil {
+				t.Errorf("Parse() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	seen := map[string]bool{}
+	tests := []struct {
+		name string
+		i    int
+		want string
+	}{
+		{"id", 0, "Id"},
+		{"", 1, "A1"},
+		{"_", 2, "A2"},
+		{"Id", 3, "A3"},
+		{"ünits", 4, "Ünits"},
+	}
+	for _, tt := range tests {
+		if got := fieldName(tt.name, "A", tt.i, seen); got != tt.want {
+			t.Errorf("fieldName(%q, %d) = %v, want %v", tt.name, tt.i, got, tt.want)
+		}
+	}
+}
+
+func TestMiddlewareFile(t *testing.T) {
+	pkg, intf := newTestInterface()
+	src := generate(t, pkg, intf)
+
+	expects := []string{
+		"type ServiceInvocation struct {\n\tContext context.Context\n\tMethod  string\n\tArgs    any\n\tResults any\n\tErr     error\n}",
+		"type ServiceHandler func(inv *ServiceInvocation)",
+		"type ServiceMiddleware func(next ServiceHandler) ServiceHandler",
+		"type ServiceGetArgs struct {\n\tId string\n}",
+		"type ServiceGetResults struct {\n\tR0 *User\n}",
+		"type ServiceNotifyArgs struct {\n\tMsgs []string\n}",
+		"type ChainedService struct {\n\tNext       Service\n\tMiddleware []ServiceMiddleware\n}",
+		`func (w *ChainedService) Get(a0 context.Context, a1 string) (*User, error) {
+	inv := &ServiceInvocation{Context: a0, Method: "Get", Args: &ServiceGetArgs{Id: a1}, Results: &ServiceGetResults{}}
+	w.handle(inv, func(inv *ServiceInvocation) {
+		args := inv.Args.(*ServiceGetArgs)
+		results := inv.Results.(*ServiceGetResults)
+		results.R0, inv.Err = w.Next.Get(inv.Context, args.Id)
+	})
+	results := inv.Results.(*ServiceGetResults)
+	return results.R0, inv.Err
+}`,
+		`func (w *ChainedService) Notify(a0 ...string) {
+	inv := &ServiceInvocation{Context: context.Background(), Method: "Notify", Args: &ServiceNotifyArgs{Msgs: a0}, Results: &ServiceNotifyResults{}}
+	w.handle(inv, func(inv *ServiceInvocation) {
+		args := inv.Args.(*ServiceNotifyArgs)
+		w.Next.Notify(args.Msgs...)
+	})
+}`,
+		"for i := len(w.Middleware) - 1; i >= 0; i-- {\n\t\th = w.Middleware[i](h)\n\t}\n\th(inv)",
+		"func NewChainedService(next Service, middleware ...ServiceMiddleware) *ChainedService {\n\treturn &ChainedService{Next: next, Middleware: middleware}\n}",
+		"var _ Service = (*ChainedService)(nil)",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(src, expect) {
+			t.Errorf("middlewareFile() missing %q\n%s", expect, src)
+		}
+	}
+}
+
+func TestMiddlewareFileGeneric(t *testing.T) {
+	pkg, intf := newTestGenericInterface()
+	src := generate(t, pkg, intf)
+
+	expects := []string{
+		"type CacheInvocation struct {",
+		"type CacheGetArgs[K comparable, V any] struct {\n\tKey K\n}",
+		"type CacheGetResults[K comparable, V any] struct {\n\tR0 V\n\tR1 bool\n}",
+		"type ChainedCache[K comparable, V any] struct {\n\tNext       Cache[K, V]\n\tMiddleware []CacheMiddleware\n}",
+		"results.R0, results.R1 = w.Next.Get(args.Key)",
+		"args := inv.Args.(*CacheGetArgs[K, V])",
+		"func NewChainedCache[K comparable, V any](next Cache[K, V], middleware ...CacheMiddleware) *ChainedCache[K, V] {\n\treturn &ChainedCache[K, V]{Next: next, Middleware: middleware}\n}",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(src, expect) {
+			t.Errorf("middlewareFile() missing %q\n%s", expect, src)
+		}
+	}
+}
+
+// newTestInterface returns the interface
+//
+//	type Service interface {
+//		Get(ctx context.Context, id string) (*User, error)
+//		Notify(msgs ...string)
+//	}
+func newTestInterface() (*model.Package, *model.Interface) {
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	pkg := &model.Package{
+		Name:         "app",
+		Path:         "example.com/app",
+		Dependencies: model.NewPackageMap("app", "example.com/app"),
+	}
+	pkg.Dependencies.Add(gen.ContextPkg.Path(), *gen.ContextPkg)
+
+	user := model.NewTypeNamed(appPkg, "User", model.NewTypeStruct(nil))
+	methods := []*model.Func{
+		model.NewFunc("Get", model.NewTypeSignature(
+			[]*model.Parameter{
+				model.NewParameter("ctx", model.NewTypeNamed(gen.ContextPkg, "Context", model.NewTypeInterface(nil, nil))),
+				model.NewParameter("id", model.NewTypeBasic("string")),
+			},
+			nil,
+			[]*model.Parameter{
+				model.NewParameter("", model.NewPointer(user)),
+				model.NewParameter("", gen.ErrorType),
+			},
+		), ""),
+		model.NewFunc("Notify", model.NewTypeSignature(
+			nil,
+			model.NewParameter("msgs", model.NewTypeBasic("string")),
+			nil,
+		), ""),
+	}
+	return pkg, model.NewInterface("Service", appPkg, methods)
+}
+
+// newTestGenericInterface returns the interface
+//
+//	type Cache[K comparable, V any] interface {
+//		Get(key K) (V, bool)
+//	}
+func newTestGenericInterface() (*model.Package, *model.Interface) {
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	pkg := &model.Package{
+		Name:         "app",
+		Path:         "example.com/app",
+		Dependencies: model.NewPackageMap("app", "example.com/app"),
+	}
+	k := model.NewTypeParameter("K", model.NewTypeConstraint("comparable"), 0)
+	v := model.NewTypeParameter("V", model.ConstraintAny, 1)
+	methods := []*model.Func{
+		model.NewFunc("Get", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("key", k)},
+			nil,
+			[]*model.Parameter{model.NewParameter("", v), model.NewParameter("", model.NewTypeBasic("bool"))},
+		), ""),
+	}
+	return pkg, model.NewGenericInterface("Cache", appPkg, methods, []*model.TypeParameter{k, v})
+}
+
+// generate returns the formatted code of the middleware chain.
+func generate(t *testing.T, pkg *model.Package, intf *model.Interface) string {
+	t.Helper()
+	file, err := middlewareFile(pkg, intf, "", model.NewPkgInfo("app", "example.com/app", ""))
+	if err != nil {
+		t.Fatalf("middlewareFile() error = %v", err)
+	}
+	code := file.PrintCode()
+	src, err := format.Source([]byte(code))
+	if err != nil {
+		t.Fatalf("middlewareFile() generated invalid code: %v\n%s", err, code)
+	}
+	return string(src)
+}
+
+func TestMiddlewareFileNameConflict(t *testing.T) {
+	appPkg := model.NewPkgInfo("app", "example.com/app", "")
+	pkg := &model.Package{
+		Name:         "app",
+		Path:         "example.com/app",
+		Dependencies: model.NewPackageMap("app", "example.com/app"),
+	}
+	for _, name := range []string{chainNextName, chainMiddlewareName} {
+		intf := model.NewInterface("Iterator", appPkg, []*model.Func{
+			model.NewFunc(name, model.NewTypeSignature(nil, nil, []*model.Parameter{model.NewParameter("", model.NewTypeBasic("bool"))}), ""),
+		})
+		_, err := middlewareFile(pkg, intf, "", appPkg)
+		want := "ChainedIterator cannot have both the field and the method named " + name
+		if err == nil || err.Error() != want {
+			t.Errorf("middlewareFile(%s) error = %v, want %v", name, err, want)
+		}
+	}
+}
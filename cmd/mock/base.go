@@ -0,0 +1,149 @@
+package mock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+const errUnimplementedName = "ErrUnimplemented"
+
+func getNopName(intfName string) string {
+	return "Nop" + intfName
+}
+
+func getUnimplementedName(intfName string) string {
+	return "Unimplemented" + intfName
+}
+
+func getBaseResultsName(i int) string {
+	return "r" + strconv.Itoa(i)
+}
+
+// basefile returns the file which has the base implementation of targetIntf of kind, KindNop or KindUnimplemented.
+// With KindUnimplemented, ErrUnimplemented is declared too unless errDecl is false.
+func basefile(targetPkg *model.Package, targetIntf *model.Interface, outFile, outPkgName, selfPkgPath string, kind Kind, errDecl bool) *model.File {
+	// output
+	outPkgName, outPkgPath := gen.OutPackage(targetPkg, outFile, outPkgName, selfPkgPath)
+	outPkg := model.NewPkgInfo(outPkgName, outPkgPath, "")
+
+	// create file which has the base implementation.
+	file := model.NewFile(outFile, outPkgName, outPkgPath, targetPkg.CopyDependencies())
+	file.DependenciesTidy()
+
+	var errVar *model.Var
+	if kind == KindUnimplemented && errDecl {
+		file.AddImport(errorsPkg)
+		errVar = model.NewVar(errUnimplementedName, nil, "")
+		errVar.SetDoc(errUnimplementedName + " is the error of the methods which are not implemented.")
+		file.AddVar(errVar)
+	}
+	if kind == KindUnimplemented {
+		file.AddImport(fmtPkg)
+	}
+
+	impl, intfType := baseImpl(targetIntf, outPkg, kind)
+	file.AddStruct(impl)
+	file.AddAssertion(model.NewAssertion(intfType, impl.Type(), false))
+
+	file.DependenciesTidy()
+
+	setBaseStatements(targetIntf, outPkg, impl, kind, errVar, *file.Dependencies())
+	return file
+}
+
+// baseImpl returns the empty struct implementing targetIntf, whose methods do nothing with KindNop,
+// or report that they are not implemented with KindUnimplemented.
+// It also returns the interface referred from outPkg.
+func baseImpl(targetIntf *model.Interface, outPkg *model.PkgInfo, kind Kind) (*model.Struct, *model.TypeNamed) {
+	name := getNopName(targetIntf.Name())
+	if kind == KindUnimplemented {
+		name = getUnimplementedName(targetIntf.Name())
+	}
+	impl := model.NewStruct(name, outPkg)
+	intfType := targetIntf.Type()
+	if targetIntf.IsGeneric() {
+		impl = model.NewGenericStruct(name, outPkg, targetIntf.TypeParams())
+		intfType = model.NewGenericTypeNamed(targetIntf.Type().Pkg(), targetIntf.Name(), targetIntf.Type().Org(), targetIntf.TypeParams())
+	}
+
+	intfRef := gen.IntfRef(targetIntf, outPkg.Path())
+	if kind == KindUnimplemented {
+		impl.SetDoc(fmt.Sprintf(`%s implements [%s] with the methods which are not implemented.
+The methods returning error return %s wrapped with the method name, and the others panic with it.
+Embed it and override the methods to implement, so that adding a method to %s does not break the build.`,
+			name, intfRef, errUnimplementedName, targetIntf.Name()))
+	} else {
+		impl.SetDoc(fmt.Sprintf(`%s implements [%s] with the methods which do nothing and return zero values.
+Embed it and override the methods of interest.`, name, intfRef))
+	}
+
+	// value receiver, so that both the struct and its pointer implement the interface.
+	rcv := model.NewParameter("", impl.Type())
+	for _, intfMethod := range targetIntf.Methods() {
+		method := model.NewMethod(rcv, intfMethod.Name(), baseSignature(intfMethod.Type()), "")
+		switch {
+		case kind == KindNop && len(intfMethod.Type().Results()) == 0:
+			method.SetDoc(fmt.Sprintf("%s implements [%s.%s] by doing nothing.", intfMethod.Name(), intfRef, intfMethod.Name()))
+		case kind == KindNop:
+			method.SetDoc(fmt.Sprintf("%s implements [%s.%s] by returning zero values.", intfMethod.Name(), intfRef, intfMethod.Name()))
+		case gen.ErrorResult(intfMethod.Type()) >= 0:
+			method.SetDoc(fmt.Sprintf("%s implements [%s.%s] by returning %s.", intfMethod.Name(), intfRef, intfMethod.Name(), errUnimplementedName))
+		default:
+			method.SetDoc(fmt.Sprintf("%s implements [%s.%s] by panicking with %s.", intfMethod.Name(), intfRef, intfMethod.Name(), errUnimplementedName))
+		}
+		impl.AddMethod(method)
+	}
+	return impl, intfType
+}
+
+// baseSignature returns the signature whose parameters are named a0, a1, ... and whose results are named r0, r1, ...,
+// so that the zero values are returned by the bare return.
+func baseSignature(org *model.TypeSignature) *model.TypeSignature {
+	sig := fmtSignature(org)
+	results := []*model.Parameter{}
+	for i, r := range sig.Results() {
+		results = append(results, model.NewParameter(getBaseResultsName(i), r.Type()))
+	}
+	return model.NewTypeSignature(sig.Args(), sig.Variadic(), results)
+}
+
+// setBaseStatements sets statements of the methods of the struct returned by baseImpl, and the value of errVar if it is not nil.
+func setBaseStatements(targetIntf *model.Interface, outPkg *model.PkgInfo, impl *model.Struct, kind Kind, errVar *model.Var, pm model.PackageMap) {
+	errorsQ, fmtQ := gen.Qualifier(pm, errorsPkg, outPkg.Path()), gen.Qualifier(pm, fmtPkg, outPkg.Path())
+	if errVar != nil {
+		errVar.SetValue(errorsQ + `New("unimplemented")`)
+	}
+
+	/*
+		return r0, fmt.Errorf("Store.Get: %w", ErrUnimplemented)
+
+		panic(fmt.Errorf("Store.Count: %w", ErrUnimplemented))
+	*/
+	for i, intfMethod := range targetIntf.Methods() {
+		if kind == KindNop {
+			if len(intfMethod.Type().Results()) > 0 {
+				impl.Methods()[i].SetStatements("return")
+			}
+			continue
+		}
+		err := fmt.Sprintf("%sErrorf(%q, %s)", fmtQ, targetIntf.Name()+"."+intfMethod.Name()+": %w", errUnimplementedName)
+		errIndex := gen.ErrorResult(intfMethod.Type())
+		if errIndex < 0 {
+			impl.Methods()[i].SetStatements("panic(" + err + ")")
+			continue
+		}
+		results := []string{}
+		for j := range intfMethod.Type().Results() {
+			if j == errIndex {
+				results = append(results, err)
+				continue
+			}
+			results = append(results, getBaseResultsName(j))
+		}
+		impl.Methods()[i].SetStatements("return " + strings.Join(results, ", "))
+	}
+}
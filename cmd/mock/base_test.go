@@ -0,0 +1,160 @@
+package mock
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestParseKind(t *testing.T) {
+	for _, s := range []string{"mock", "nop", "unimplemented", "func"} {
+		if got, err := ParseKind(s); err != nil || string(got) != s {
+			t.Errorf("ParseKind(%q) = %v, %v, want %v, nil", s, got, err, s)
+		}
+	}
+	if _, err := ParseKind("fake"); err == nil {
+		t.Error("ParseKind() should return error for invalid kind")
+	}
+}
+
+func TestBasefile(t *testing.T) {
+	pkg, intf := newBaseTestInterface()
+
+	tests := []struct {
+		name    string
+		kind    Kind
+		errDecl bool
+		expects []string
+		ignores []string
+	}{
+		{
+			name: "nop",
+			kind: KindNop,
+			expects: []string{
+				"type NopStore struct {\n}",
+				"// Get implements [Store.Get] by returning zero values.\nfunc (NopStore) Get(a0 context.Context, a1 string) (r0 *User, r1 error) {\n\treturn\n}",
+				"// Reset implements [Store.Reset] by doing nothing.\nfunc (NopStore) Reset(a0 ...string) {\n}",
+				"var _ Store = NopStore{}",
+			},
+			ignores: []string{"ErrUnimplemented", `"fmt"`},
+		},
+		{
+			name:    "unimplemented",
+			kind:    KindUnimplemented,
+			errDecl: true,
+			expects: []string{
+				"var ErrUnimplemented = errors.New(\"unimplemented\")",
+				"type UnimplementedStore struct {\n}",
+				"func (UnimplementedStore) Get(a0 context.Context, a1 string) (r0 *User, r1 error) {\n\treturn r0, fmt.Errorf(\"Store.Get: %w\", ErrUnimplemented)\n}",
+				"func (UnimplementedStore) Put(a0 *User) (r0 error) {\n\treturn fmt.Errorf(\"Store.Put: %w\", ErrUnimplemented)\n}",
+				"func (UnimplementedStore) Count() (r0 int) {\n\tpanic(fmt.Errorf(\"Store.Count: %w\", ErrUnimplemented))\n}",
+				"func (UnimplementedStore) Reset(a0 ...string) {\n\tpanic(fmt.Errorf(\"Store.Reset: %w\", ErrUnimplemented))\n}",
+				"var _ Store = UnimplementedStore{}",
+			},
+		},
+		{
+			name:    "unimplemented without declaration",
+			kind:    KindUnimplemented,
+			errDecl: false,
+			expects: []string{
+				"return r0, fmt.Errorf(\"Store.Get: %w\", ErrUnimplemented)",
+			},
+			ignores: []string{"var ErrUnimplemented", `"errors"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := basefile(pkg, intf, "", "", "", tt.kind, tt.errDecl)
+			src, err := format.Source([]byte(file.PrintCode()))
+			if err != nil {
+				t.Fatalf("basefile() generated invalid code: %v\n%s", err, file.PrintCode())
+			}
+			for _, expect := range tt.expects {
+				if !strings.Contains(string(src), expect) {
+					t.Errorf("basefile() missing %q\n%s", expect, src)
+				}
+			}
+			for _, ignore := range tt.ignores {
+				if strings.Contains(string(src), ignore) {
+					t.Errorf("basefile() should not contain %q\n%s", ignore, src)
+				}
+			}
+		})
+	}
+}
+
+func TestBasefileGeneric(t *testing.T) {
+	pkg := &model.Package{
+		Name:         "store",
+		Path:         "example.com/store",
+		Dependencies: model.NewPackageMap("store", "example.com/store"),
+	}
+	k := model.NewTypeParameter("K", model.ConstraintComparable, 0)
+	v := model.NewTypeParameter("V", model.ConstraintAny, 1)
+	methods := []*model.Func{
+		model.NewFunc("Get", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("key", k)},
+			nil,
+			[]*model.Parameter{model.NewParameter("", v), model.NewParameter("", gen.ErrorType)},
+		), ""),
+	}
+	intf := model.NewGenericInterface("Cache", model.NewPkgInfo("store", "example.com/store", ""), methods, []*model.TypeParameter{k, v})
+
+	src, err := format.Source([]byte(basefile(pkg, intf, "", "", "", KindUnimplemented, true).PrintCode()))
+	if err != nil {
+		t.Fatalf("basefile() generated invalid code: %v", err)
+	}
+	expects := []string{
+		"type UnimplementedCache[K comparable, V any] struct {\n}",
+		"func (UnimplementedCache[K, V]) Get(a0 K) (r0 V, r1 error) {",
+		"func _[K comparable, V any]() {\n\tvar _ Cache[K, V] = UnimplementedCache[K, V]{}\n}",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(string(src), expect) {
+			t.Errorf("basefile() missing %q\n%s", expect, src)
+		}
+	}
+}
+
+// newBaseTestInterface returns the interface
+//
+//	type Store interface {
+//		Get(ctx context.Context, id string) (*User, error)
+//		Put(u *User) error
+//		Count() int
+//		Reset(keys ...string)
+//	}
+func newBaseTestInterface() (*model.Package, *model.Interface) {
+	storePkg := model.NewPkgInfo("store", "example.com/store", "")
+	pkg := &model.Package{
+		Name:         "store",
+		Path:         "example.com/store",
+		Dependencies: model.NewPackageMap("store", "example.com/store"),
+	}
+	pkg.Dependencies.Add(gen.ContextPkg.Path(), *gen.ContextPkg)
+
+	user := model.NewPointer(model.NewTypeNamed(storePkg, "User", model.NewTypeStruct(nil)))
+	methods := []*model.Func{
+		model.NewFunc("Get", model.NewTypeSignature(
+			[]*model.Parameter{
+				model.NewParameter("ctx", model.NewTypeNamed(gen.ContextPkg, "Context", model.NewTypeInterface(nil, nil))),
+				model.NewParameter("id", model.NewTypeBasic("string")),
+			},
+			nil,
+			[]*model.Parameter{model.NewParameter("", user), model.NewParameter("", gen.ErrorType)},
+		), ""),
+		model.NewFunc("Put", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("u", user)},
+			nil,
+			[]*model.Parameter{model.NewParameter("", gen.ErrorType)},
+		), ""),
+		model.NewFunc("Count", model.NewTypeSignature(nil, nil,
+			[]*model.Parameter{model.NewParameter("", model.NewTypeBasic("int"))},
+		), ""),
+		model.NewFunc("Reset", model.NewTypeSignature(nil, model.NewParameter("keys", model.NewTypeBasic("string")), nil), ""),
+	}
+	return pkg, model.NewInterface("Store", storePkg, methods)
+}
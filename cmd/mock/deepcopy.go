@@ -0,0 +1,308 @@
+package mock
+
+import (
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+// deepCopier generates statements which deep-copy a value in place.
+// The generated code is driven by the model.Type tree:
+// slices, maps, pointers, arrays and structs are copied,
+// while funcs, chans, interfaces and basic types are kept as they are.
+// The recursive named types are copied by the generated funcs calling themselves,
+// which are created by addFuncs before the imports of the file are resolved.
+type deepCopier struct {
+	myPkgPath string
+	pm        model.PackageMap
+	visiting  map[*model.TypeNamed]bool        // for cycle detection
+	funcs     map[*model.TypeNamed]*model.Func // funcs deep-copying the recursive types
+	types     []*model.TypeNamed               // keys of funcs in the order they are added
+	names     map[string]bool
+}
+
+func newDeepCopier(myPkgPath string) *deepCopier {
+	return &deepCopier{
+		myPkgPath: myPkgPath,
+		visiting:  map[*model.TypeNamed]bool{},
+		funcs:     map[*model.TypeNamed]*model.Func{},
+		names:     map[string]bool{},
+	}
+}
+
+// addFuncs creates the funcs deep-copying the recursive named types reachable from typ and returns the new ones.
+// A recursive type which cannot be referred from the output package, such as an unexported type of another package
+// or a type instantiated with type parameters, has no func and is copied only one level deep.
+func (dc *deepCopier) addFuncs(typ model.Type, owner *model.TypeNamed) []*model.Func {
+	switch t := typ.(type) {
+	case *model.TypeArray:
+		return dc.addFuncs(t.Type(), owner)
+	case *model.TypeMap:
+		// keys are not copied.
+		return dc.addFuncs(t.Value(), owner)
+	case *model.TypePointer:
+		return dc.addFuncs(t.Type(), owner)
+	case *model.TypeStruct:
+		funcs := []*model.Func{}
+		for _, f := range t.Fields() {
+			name := f.Name()
+			if name == "" {
+				name = embeddedName(f.Type())
+			}
+			if name == "" || !dc.accessible(name, owner) {
+				continue
+			}
+			funcs = append(funcs, dc.addFuncs(f.Type(), owner)...)
+		}
+		return funcs
+	case *model.TypeNamed:
+		if dc.funcs[t] != nil || dc.visiting[t] {
+			return nil
+		}
+		dc.visiting[t] = true
+		defer delete(dc.visiting, t)
+
+		funcs := []*model.Func{}
+		if dc.referable(t) && reaches(t.Org(), t, map[*model.TypeNamed]bool{}) {
+			fn := model.NewFunc(dc.funcName(t), model.NewTypeSignature(
+				[]*model.Parameter{model.NewParameter("x", model.NewPointer(t))}, nil, nil,
+			), "")
+			fn.SetDoc(fn.Name() + " replaces *x with a deep copy of it.")
+			dc.funcs[t] = fn
+			dc.types = append(dc.types, t)
+			funcs = append(funcs, fn)
+		}
+		return append(funcs, dc.addFuncs(t.Org(), t)...)
+	default:
+		return nil
+	}
+}
+
+// resolve sets the package map of the file whose imports are resolved, and the statements of the funcs.
+func (dc *deepCopier) resolve(pm model.PackageMap) {
+	dc.pm = pm
+	for _, t := range dc.types {
+		/*
+			if x.Next != nil {
+				v0 := *x.Next
+				deepCopyNode(&v0)
+				x.Next = &v0
+			}
+		*/
+		x := "(*x)"
+		if _, ok := t.Org().(*model.TypeStruct); ok {
+			x = "x"
+		}
+		dc.funcs[t].SetStatements(strings.TrimSuffix(dc.copyStmts(x, t.Org(), t, 0), "\n"))
+	}
+}
+
+// funcName returns the name of the func deep-copying t, which is not used by the other funcs.
+func (dc *deepCopier) funcName(t *model.TypeNamed) string {
+	name := "deepCopy" + strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+	for i := 1; dc.names[name]; i++ {
+		name = "deepCopy" + strings.ToUpper(t.Name()[:1]) + t.Name()[1:] + strconv.Itoa(i)
+	}
+	dc.names[name] = true
+	return name
+}
+
+// referable reports whether t can be referred from the output package by its name.
+func (dc *deepCopier) referable(t *model.TypeNamed) bool {
+	if t.Pkg() == nil || t.IsGeneric() {
+		return false
+	}
+	if !token.IsExported(t.Name()) && t.Pkg().Path() != dc.myPkgPath {
+		return false
+	}
+	for _, arg := range t.TypeArgs() {
+		if hasTypeParam(arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// reaches reports whether target is reachable from typ through the types which are copied.
+func reaches(typ model.Type, target *model.TypeNamed, seen map[*model.TypeNamed]bool) bool {
+	switch t := typ.(type) {
+	case *model.TypeArray:
+		return reaches(t.Type(), target, seen)
+	case *model.TypeMap:
+		return reaches(t.Value(), target, seen)
+	case *model.TypePointer:
+		return reaches(t.Type(), target, seen)
+	case *model.TypeStruct:
+		for _, f := range t.Fields() {
+			if reaches(f.Type(), target, seen) {
+				return true
+			}
+		}
+		return false
+	case *model.TypeNamed:
+		if t == target {
+			return true
+		}
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		return reaches(t.Org(), target, seen)
+	default:
+		return false
+	}
+}
+
+// hasTypeParam reports whether typ refers to a type parameter.
+func hasTypeParam(typ model.Type) bool {
+	switch t := typ.(type) {
+	case *model.TypeParameter:
+		return true
+	case *model.TypeArray:
+		return hasTypeParam(t.Type())
+	case *model.TypeMap:
+		return hasTypeParam(t.Key()) || hasTypeParam(t.Value())
+	case *model.TypePointer:
+		return hasTypeParam(t.Type())
+	case *model.TypeNamed:
+		for _, arg := range t.TypeArgs() {
+			if hasTypeParam(arg) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// needsCopy reports whether a value of typ shares memory with its copy.
+func (dc *deepCopier) needsCopy(typ model.Type) bool {
+	switch t := typ.(type) {
+	case *model.TypeArray:
+		if t.Len() < 0 {
+			return true
+		}
+		return dc.needsCopy(t.Type())
+	case *model.TypeMap:
+		return true
+	case *model.TypePointer:
+		return true
+	case *model.TypeStruct:
+		for _, f := range t.Fields() {
+			if dc.needsCopy(f.Type()) {
+				return true
+			}
+		}
+		return false
+	case *model.TypeNamed:
+		if dc.visiting[t] {
+			return false
+		}
+		dc.visiting[t] = true
+		defer delete(dc.visiting, t)
+		return dc.needsCopy(t.Org())
+	default:
+		// basic types, funcs, chans, interfaces and type parameters are kept by reference.
+		return false
+	}
+}
+
+// copyStmts returns statements which replace the value of the addressable
+// expression x with a deep copy of it.
+// owner is the named type whose fields are accessed, and depth is used to
+// create non-conflicting variable names.
+func (dc *deepCopier) copyStmts(x string, typ model.Type, owner *model.TypeNamed, depth int) string {
+	if !dc.needsCopy(typ) {
+		return ""
+	}
+	i := "i" + strconv.Itoa(depth)
+	k := "k" + strconv.Itoa(depth)
+	v := "v" + strconv.Itoa(depth)
+	src := "s" + strconv.Itoa(depth)
+
+	switch t := typ.(type) {
+	case *model.TypeArray:
+		elem := dc.copyStmts(x+"["+i+"]", t.Type(), owner, depth+1)
+		if t.Len() >= 0 {
+			return fmt.Sprintf("for %s := range %s {\n%s}\n", i, x, elem)
+		}
+		s := fmt.Sprintf("if %s != nil {\n", x)
+		s += fmt.Sprintf("%s = append(%s[:0:0], %s...)\n", x, x, x)
+		if elem != "" {
+			s += fmt.Sprintf("for %s := range %s {\n%s}\n", i, x, elem)
+		}
+		s += "}\n"
+		return s
+
+	case *model.TypeMap:
+		s := fmt.Sprintf("if %s != nil {\n", x)
+		s += fmt.Sprintf("%s := %s\n", src, x)
+		s += fmt.Sprintf("%s = make(%s, len(%s))\n", x, t.PrintType(dc.myPkgPath, dc.pm), src)
+		s += fmt.Sprintf("for %s, %s := range %s {\n", k, v, src)
+		s += dc.copyStmts(v, t.Value(), owner, depth+1)
+		s += fmt.Sprintf("%s[%s] = %s\n", x, k, v)
+		s += "}\n"
+		s += "}\n"
+		return s
+
+	case *model.TypePointer:
+		s := fmt.Sprintf("if %s != nil {\n", x)
+		s += fmt.Sprintf("%s := *%s\n", v, x)
+		s += dc.copyStmts(v, t.Type(), owner, depth+1)
+		s += fmt.Sprintf("%s = &%s\n", x, v)
+		s += "}\n"
+		return s
+
+	case *model.TypeStruct:
+		s := ""
+		for _, f := range t.Fields() {
+			name := f.Name()
+			if name == "" {
+				name = embeddedName(f.Type())
+			}
+			if name == "" || !dc.accessible(name, owner) {
+				continue
+			}
+			s += dc.copyStmts(x+"."+name, f.Type(), owner, depth)
+		}
+		return s
+
+	case *model.TypeNamed:
+		if fn := dc.funcs[t]; fn != nil {
+			return fmt.Sprintf("%s(&%s)\n", fn.Name(), x)
+		}
+		dc.visiting[t] = true
+		defer delete(dc.visiting, t)
+		return dc.copyStmts(x, t.Org(), t, depth)
+
+	default:
+		return ""
+	}
+}
+
+// accessible reports whether the field of the owner can be referred from the output package.
+func (dc *deepCopier) accessible(field string, owner *model.TypeNamed) bool {
+	if token.IsExported(field) {
+		return true
+	}
+	if owner == nil || owner.Pkg() == nil {
+		return true
+	}
+	return owner.Pkg().Path() == dc.myPkgPath
+}
+
+// embeddedName returns the field name of the embedded type.
+func embeddedName(typ model.Type) string {
+	switch t := typ.(type) {
+	case *model.TypePointer:
+		return embeddedName(t.Type())
+	case *model.TypeNamed:
+		return t.Name()
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,207 @@
+package mock
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestDeepCopierNeedsCopy(t *testing.T) {
+	pkg := model.NewPkgInfo("testpkg", "example.com/testpkg", "")
+	node := model.NewTypeNamed(pkg, "Node", nil)
+	*node = *model.NewTypeNamed(pkg, "Node", model.NewTypeStruct([]*model.Field{
+		model.NewField("Next", model.NewPointer(node), ""),
+	}))
+
+	tests := []struct {
+		name     string
+		typ      model.Type
+		expected bool
+	}{
+		{"basic", model.NewTypeBasic("int"), false},
+		{"slice", model.NewTypeArray(-1, model.NewTypeBasic("int")), true},
+		{"array of basic", model.NewTypeArray(2, model.NewTypeBasic("int")), false},
+		{"array of slice", model.NewTypeArray(2, model.NewTypeArray(-1, model.NewTypeBasic("int"))), true},
+		{"map", model.NewTypeMap(model.NewTypeBasic("string"), model.NewTypeBasic("int")), true},
+		{"pointer", model.NewPointer(model.NewTypeBasic("int")), true},
+		{"func", model.NewTypeSignature(nil, nil, nil), false},
+		{"chan", model.NewTypeChan(model.SendRecv, model.NewTypeArray(-1, model.NewTypeBasic("int"))), false},
+		{"interface", model.NewTypeInterface(nil, nil), false},
+		{"recursive named", node, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := newDeepCopier("example.com/testpkg")
+			if got := dc.needsCopy(tt.typ); got != tt.expected {
+				t.Errorf("needsCopy() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDeepCopierCopyStmts(t *testing.T) {
+	pkg := model.NewPkgInfo("testpkg", "example.com/testpkg", "")
+	other := model.NewPkgInfo("other", "example.com/other", "")
+	item := model.NewTypeNamed(pkg, "Item", model.NewTypeStruct([]*model.Field{
+		model.NewField("Tags", model.NewTypeArray(-1, model.NewTypeBasic("string")), ""),
+		model.NewField("attrs", model.NewTypeMap(model.NewTypeBasic("string"), model.NewTypeBasic("int")), ""),
+		model.NewField("Fn", model.NewTypeSignature(nil, nil, nil), ""),
+	}))
+	foreign := model.NewTypeNamed(other, "Item", model.NewTypeStruct([]*model.Field{
+		model.NewField("Tags", model.NewTypeArray(-1, model.NewTypeBasic("string")), ""),
+		model.NewField("attrs", model.NewTypeMap(model.NewTypeBasic("string"), model.NewTypeBasic("int")), ""),
+	}))
+
+	tests := []struct {
+		name        string
+		typ         model.Type
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "slice",
+			typ:      model.NewTypeArray(-1, model.NewTypeBasic("int")),
+			contains: []string{"x = append(x[:0:0], x...)"},
+		},
+		{
+			name:     "map of slices",
+			typ:      model.NewTypeMap(model.NewTypeBasic("string"), model.NewTypeArray(-1, model.NewTypeBasic("int"))),
+			contains: []string{"x = make(map[string][]int, len(s0))", "v0 = append(v0[:0:0], v0...)", "x[k0] = v0"},
+		},
+		{
+			name:        "pointer to struct",
+			typ:         model.NewPointer(item),
+			contains:    []string{"v0 := *x", "v0.Tags = append(", "v0.attrs = make(map[string]int, len(s1))", "x = &v0"},
+			notContains: []string{"Fn"},
+		},
+		{
+			name:        "unexported field of other package",
+			typ:         model.NewPointer(foreign),
+			contains:    []string{"v0.Tags = append("},
+			notContains: []string{"attrs"},
+		},
+		{
+			name:     "basic",
+			typ:      model.NewTypeBasic("int"),
+			contains: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm := model.NewPackageMap("testpkg", "example.com/testpkg")
+			pm.Add(other.Path(), *other)
+			dc := newDeepCopier("example.com/testpkg")
+			dc.resolve(*pm)
+			stmts := dc.copyStmts("x", tt.typ, nil, 0)
+
+			if _, err := format.Source([]byte("package p\nfunc f() {\n" + stmts + "}\n")); err != nil {
+				t.Fatalf("copyStmts() generated invalid code: %v\n%s", err, stmts)
+			}
+			for _, c := range tt.contains {
+				if !strings.Contains(stmts, c) {
+					t.Errorf("copyStmts() should contain %q\n%s", c, stmts)
+				}
+			}
+			for _, c := range tt.notContains {
+				if strings.Contains(stmts, c) {
+					t.Errorf("copyStmts() should not contain %q\n%s", c, stmts)
+				}
+			}
+		})
+	}
+}
+
+func TestDeepCopierFuncs(t *testing.T) {
+	pkg := model.NewPkgInfo("testpkg", "example.com/testpkg", "")
+	other := model.NewPkgInfo("other", "example.com/other", "")
+	node := model.NewTypeNamed(pkg, "Node", nil)
+	*node = *model.NewTypeNamed(pkg, "Node", model.NewTypeStruct([]*model.Field{
+		model.NewField("Next", model.NewPointer(node), ""),
+		model.NewField("Tags", model.NewTypeArray(-1, model.NewTypeBasic("string")), ""),
+	}))
+	tree := model.NewTypeNamed(pkg, "Tree", nil)
+	*tree = *model.NewTypeNamed(pkg, "Tree", model.NewTypeMap(model.NewTypeBasic("string"), tree))
+	hidden := model.NewTypeNamed(other, "node", nil)
+	*hidden = *model.NewTypeNamed(other, "node", model.NewTypeStruct([]*model.Field{
+		model.NewField("Next", model.NewPointer(hidden), ""),
+	}))
+
+	pm := model.NewPackageMap("testpkg", "example.com/testpkg")
+	pm.Add(other.Path(), *other)
+	dc := newDeepCopier("example.com/testpkg")
+	funcs := []*model.Func{}
+	for _, typ := range []model.Type{model.NewPointer(node), node, tree, model.NewPointer(hidden)} {
+		funcs = append(funcs, dc.addFuncs(typ, nil)...)
+	}
+	dc.resolve(*pm)
+
+	if len(funcs) != 2 {
+		t.Fatalf("addFuncs() returned %d funcs, want 2", len(funcs))
+	}
+	code := ""
+	for _, fn := range funcs {
+		code += fn.PrintCode("example.com/testpkg", *pm)
+	}
+	code += "func f(x *Node) {\n" + dc.copyStmts("x", model.NewPointer(node), nil, 0) + "}\n"
+	code += "func g(x *other.node) {\n" + dc.copyStmts("x", model.NewPointer(hidden), nil, 0) + "}\n"
+	src, err := format.Source([]byte("package p\n" + code))
+	if err != nil {
+		t.Fatalf("generated invalid code: %v\n%s", err, code)
+	}
+
+	for _, c := range []string{
+		"// deepCopyNode replaces *x with a deep copy of it.\nfunc deepCopyNode(x *Node)",
+		"v0 := *x.Next\n\t\tdeepCopyNode(&v0)\n\t\tx.Next = &v0",
+		"x.Tags = append(x.Tags[:0:0], x.Tags...)",
+		"func deepCopyTree(x *Tree)",
+		"s0 := (*x)",
+		"deepCopyTree(&v0)",
+		// the caller copies the pointer and lets the func copy the rest.
+		"func f(x *Node) {\n\tif x != nil {\n\t\tv0 := *x\n\t\tdeepCopyNode(&v0)\n\t\tx = &v0",
+		// the unexported type of the other package is copied one level deep.
+		"func g(x *other.node) {\n\tif x != nil {\n\t\tv0 := *x\n\t\tif v0.Next != nil {",
+	} {
+		if !strings.Contains(string(src), c) {
+			t.Errorf("generated code should contain %q\n%s", c, src)
+		}
+	}
+}
+
+func TestMockfileRecord(t *testing.T) {
+	pkg := &model.Package{
+		Name:         "testpkg",
+		Path:         "example.com/testpkg",
+		Dependencies: model.NewPackageMap("testpkg", "example.com/testpkg"),
+	}
+	methods := []*model.Func{
+		model.NewFunc("Put", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("items", model.NewTypeArray(-1, model.NewTypeBasic("string")))},
+			nil,
+			[]*model.Parameter{model.NewParameter("", model.NewTypeBasic("error"))},
+		), ""),
+	}
+	intf := model.NewInterface("Store", model.NewPkgInfo(pkg.Name, pkg.Path, ""), methods)
+
+	code := mockfile(pkg, intf, "", "", "", options{record: true, deepCopy: true}).PrintCode()
+	src, err := format.Source([]byte(code))
+	if err != nil {
+		t.Fatalf("mockfile() generated invalid code: %v\n%s", err, code)
+	}
+
+	for _, c := range []string{
+		`"sync"`,
+		"CallsPut []CallPut",
+		"type CallPut struct",
+		"c := CallPut{A0: a0}",
+		"c.A0 = append(c.A0[:0:0], c.A0...)",
+		"m.CallsPut = append(m.CallsPut, c)",
+	} {
+		if !strings.Contains(string(src), c) {
+			t.Errorf("mockfile() should contain %q\n%s", c, src)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package mock
+
+import (
+	"github.com/kmio11/codegen/generator/model"
+)
+
+var (
+	bytesPkg  = model.NewPkgInfo("bytes", "bytes", "")
+	errorsPkg = model.NewPkgInfo("errors", "errors", "")
+	fmtPkg    = model.NewPkgInfo("fmt", "fmt", "")
+	jsonPkg   = model.NewPkgInfo("json", "encoding/json", "")
+	osPkg     = model.NewPkgInfo("os", "os", "")
+	sortPkg   = model.NewPkgInfo("sort", "sort", "")
+)
+
+func getStubSeqFieldName(intfMethodName string) string {
+	return intfMethodName + "Seq"
+}
+
+func getLoadStubFuncName(stubRootName string) string {
+	return "Load" + stubRootName
+}
+
+func getMustLoadStubFuncName(stubRootName string) string {
+	return "MustLoad" + stubRootName
+}
+
+// isErrorType returns true if typ is the predeclared error type.
+func isErrorType(typ model.Type) bool {
+	t, ok := typ.(*model.TypeNamed)
+	return ok && t.Pkg() == nil && t.Name() == "error"
+}
+
+// stubSequences adds <Method>Seq fields to stubRoot, which hold results returned in order.
+// Once a sequence has been consumed, its last results are returned repeatedly.
+// If a sequence is empty, the results in <Method> field are returned.
+func stubSequences(targetIntf *model.Interface, stubRoot *model.Struct, stubs []*model.Struct) {
+	methods := map[string]*model.Method{}
+	for _, m := range stubRoot.Methods() {
+		methods[m.Name()] = m
+	}
+
+	for i, intfMethod := range targetIntf.Methods() {
+		seqName := getStubSeqFieldName(intfMethod.Name())
+		stubRoot.AddField(
+			model.NewField(
+				seqName,
+				model.NewTypeArray(-1, stubs[i].Type()),
+				"",
+			),
+		)
+
+		if len(stubs[i].Fields()) == 0 {
+			continue
+		}
+
+		/*
+			r := s.Xxx
+			if len(s.XxxSeq) > 0 {
+				r = s.XxxSeq[0]
+				if len(s.XxxSeq) > 1 {
+					s.XxxSeq = s.XxxSeq[1:]
+				}
+			}
+			return r.R0, r.R1
+		*/
+		seq := stubRcvName + "." + seqName
+		body := "r := " + stubRcvName + "." + intfMethod.Name() + "\n"
+		body += "if len(" + seq + ") > 0 {\n"
+		body += "r = " + seq + "[0]\n"
+		body += "if len(" + seq + ") > 1 {\n"
+		body += seq + " = " + seq + "[1:]\n"
+		body += "}\n"
+		body += "}\n"
+		body += "return "
+		for j, r := range stubs[i].Fields() {
+			if j > 0 {
+				body += ","
+			}
+			body += "r." + r.Name()
+		}
+		methods[getStubMethodName(intfMethod.Name())].SetStatements(body)
+	}
+}
+
+// fixtureImports returns packages the fixture loader refers to.
+func fixtureImports() []*model.PkgInfo {
+	return []*model.PkgInfo{bytesPkg, errorsPkg, fmtPkg, jsonPkg, osPkg, sortPkg}
+}
+
+// stubLoaders returns LoadStubXxx and MustLoadStubXxx functions.
+func stubLoaders(targetIntf *model.Interface, stubRoot *model.Struct) (load, mustLoad *model.Func) {
+	pathParam := []*model.Parameter{model.NewParameter("path", model.NewTypeBasic("string"))}
+	stubRootPtr := model.NewPointer(stubRoot.Type())
+
+	loadSig := model.NewTypeSignature(pathParam, nil, []*model.Parameter{
+		model.NewParameter("", stubRootPtr),
+		model.NewParameter("", model.NewTypeNamed(nil, "error", model.NewTypeInterface(nil, nil))),
+	})
+	mustLoadSig := model.NewTypeSignature(pathParam, nil, []*model.Parameter{
+		model.NewParameter("", stubRootPtr),
+	})
+
+	loadName := getLoadStubFuncName(stubRoot.Name())
+	mustLoadName := getMustLoadStubFuncName(stubRoot.Name())
+	if targetIntf.IsGeneric() {
+		load = model.NewGenericFunc(loadName, loadSig, "", targetIntf.TypeParams())
+		mustLoad = model.NewGenericFunc(mustLoadName, mustLoadSig, "", targetIntf.TypeParams())
+	} else {
+		load = model.NewFunc(loadName, loadSig, "")
+		mustLoad = model.NewFunc(mustLoadName, mustLoadSig, "")
+	}
+	return load, mustLoad
+}
+
+// setStubLoaderStatements sets statements of the functions returned by stubLoaders.
+func setStubLoaderStatements(targetIntf *model.Interface, outPkg *model.PkgInfo, stubRoot *model.Struct, stubs []*model.Struct, load, mustLoad *model.Func, pm model.PackageMap) {
+	q := func(pkg *model.PkgInfo) string {
+		p := pm.Get(pkg.Path())
+		if p == nil {
+			p = pkg
+		}
+		return p.Prefix(outPkg.Path())
+	}
+	bytesQ, errorsQ, fmtQ, jsonQ, osQ, sortQ := q(bytesPkg), q(errorsPkg), q(fmtPkg), q(jsonPkg), q(osPkg), q(sortPkg)
+
+	/*
+		{
+			"Add": {"R0": 1},
+			"Divide": [{"R0": 1, "R1": null}, {"R0": 0, "R1": "division by zero"}]
+		}
+	*/
+	s := "b, err := " + osQ + "ReadFile(path)\n"
+	s += "if err != nil {\nreturn nil, err\n}\n"
+	s += "var methods map[string]" + jsonQ + "RawMessage\n"
+	s += "if err := " + jsonQ + "Unmarshal(b, &methods); err != nil {\n"
+	s += "return nil, " + fmtQ + "Errorf(\"%s: %w\", path, err)\n"
+	s += "}\n"
+	s += "names := make([]string, 0, len(methods))\n"
+	s += "for name := range methods {\nnames = append(names, name)\n}\n"
+	s += sortQ + "Strings(names)\n"
+	s += "\n"
+	s += stubRcvName + " := &" + stubRoot.Type().PrintType(outPkg.Path(), pm) + "{}\n"
+	s += "for _, name := range names {\n"
+	s += "raw := methods[name]\n"
+	s += "isSeq := len(raw) > 0 && raw[0] == '['\n"
+	s += "tuples := []" + jsonQ + "RawMessage{raw}\n"
+	s += "if isSeq {\n"
+	s += "if err := " + jsonQ + "Unmarshal(raw, &tuples); err != nil {\n"
+	s += "return nil, " + fmtQ + "Errorf(\"%s: method %s: %w\", path, name, err)\n"
+	s += "}\n"
+	s += "}\n"
+	// the errors name the result field if the type of its value is wrong.
+	s += "decode := func(i int, v any) error {\n"
+	s += "dec := " + jsonQ + "NewDecoder(" + bytesQ + "NewReader(tuples[i]))\n"
+	s += "dec.DisallowUnknownFields()\n"
+	s += "if err := dec.Decode(v); err != nil {\n"
+	s += "at := name\n"
+	s += "if isSeq {\n"
+	s += "at = " + fmtQ + "Sprintf(\"%s[%d]\", name, i)\n"
+	s += "}\n"
+	s += "var typeErr *" + jsonQ + "UnmarshalTypeError\n"
+	s += "if " + errorsQ + "As(err, &typeErr) && typeErr.Field != \"\" {\n"
+	s += "return " + fmtQ + "Errorf(\"%s: method %s: field %s: %w\", path, at, typeErr.Field, err)\n"
+	s += "}\n"
+	s += "return " + fmtQ + "Errorf(\"%s: method %s: %w\", path, at, err)\n"
+	s += "}\n"
+	s += "return nil\n"
+	s += "}\n"
+	s += "\n"
+	s += "switch name {\n"
+	for i, intfMethod := range targetIntf.Methods() {
+		stub := stubs[i]
+
+		// errors are written as a string or null.
+		fields := []*model.Field{}
+		for _, f := range stub.Fields() {
+			typ := f.Type()
+			if isErrorType(typ) {
+				typ = model.NewPointer(model.NewTypeBasic("string"))
+			}
+			fields = append(fields, model.NewField(f.Name(), typ, ""))
+		}
+
+		s += "case \"" + intfMethod.Name() + "\":\n"
+		s += "for i := range tuples {\n"
+		s += "var v " + model.NewTypeStruct(fields).PrintType(outPkg.Path(), pm) + "\n"
+		s += "if err := decode(i, &v); err != nil {\nreturn nil, err\n}\n"
+		s += "r := " + stub.Type().PrintType(outPkg.Path(), pm) + "{"
+		n := 0
+		for _, f := range stub.Fields() {
+			if isErrorType(f.Type()) {
+				continue
+			}
+			if n > 0 {
+				s += ", "
+			}
+			s += f.Name() + ": v." + f.Name()
+			n++
+		}
+		s += "}\n"
+		for _, f := range stub.Fields() {
+			if isErrorType(f.Type()) {
+				s += "if v." + f.Name() + " != nil {\n"
+				s += "r." + f.Name() + " = " + errorsQ + "New(*v." + f.Name() + ")\n"
+				s += "}\n"
+			}
+		}
+		s += "if isSeq {\n"
+		s += stubRcvName + "." + getStubSeqFieldName(intfMethod.Name()) + " = append(" + stubRcvName + "." + getStubSeqFieldName(intfMethod.Name()) + ", r)\n"
+		s += "} else {\n"
+		s += stubRcvName + "." + intfMethod.Name() + " = r\n"
+		s += "}\n"
+		s += "}\n"
+	}
+	s += "default:\n"
+	s += "return nil, " + fmtQ + "Errorf(\"%s: unknown method %s\", path, name)\n"
+	s += "}\n"
+	s += "}\n"
+	s += "return " + stubRcvName + ", nil"
+	load.SetStatements(s)
+
+	call := load.Name()
+	if targetIntf.IsGeneric() {
+		call += "["
+		for i, param := range targetIntf.TypeParams() {
+			if i > 0 {
+				call += ", "
+			}
+			call += param.Name()
+		}
+		call += "]"
+	}
+	s = stubRcvName + ", err := " + call + "(path)\n"
+	s += "if err != nil {\npanic(err)\n}\n"
+	s += "return " + stubRcvName
+	mustLoad.SetStatements(s)
+}
@@ -0,0 +1,141 @@
+package mock
+
+import (
+	"encoding/json"
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func newFixtureTestInterface(typeParams []*model.TypeParameter) (*model.Package, *model.Interface) {
+	pkg := &model.Package{
+		Name:         "testpkg",
+		Path:         "example.com/testpkg",
+		Dependencies: model.NewPackageMap("testpkg", "example.com/testpkg"),
+	}
+	errType := model.NewTypeNamed(nil, "error", model.NewTypeInterface(nil, nil))
+	methods := []*model.Func{
+		model.NewFunc("Divide", model.NewTypeSignature(
+			[]*model.Parameter{
+				model.NewParameter("a", model.NewTypeBasic("int")),
+				model.NewParameter("b", model.NewTypeBasic("int")),
+			},
+			nil,
+			[]*model.Parameter{
+				model.NewParameter("", model.NewTypeBasic("int")),
+				model.NewParameter("", errType),
+			},
+		), ""),
+		model.NewFunc("Reset", model.NewTypeSignature(nil, nil, nil), ""),
+	}
+	pkgInfo := model.NewPkgInfo(pkg.Name, pkg.Path, "")
+	if len(typeParams) > 0 {
+		return pkg, model.NewGenericInterface("Calculator", pkgInfo, methods, typeParams)
+	}
+	return pkg, model.NewInterface("Calculator", pkgInfo, methods)
+}
+
+func TestMockfileFixture(t *testing.T) {
+	pkg, intf := newFixtureTestInterface(nil)
+
+	code := mockfile(pkg, intf, "", "", "", options{fixture: true}).PrintCode()
+	src, err := format.Source([]byte(code))
+	if err != nil {
+		t.Fatalf("mockfile() generated invalid code: %v\n%s", err, code)
+	}
+
+	for _, c := range []string{
+		`"encoding/json"`,
+		`"errors"`,
+		"DivideSeq []StubDivide",
+		"func LoadStubCalculator(path string) (*StubCalculator, error)",
+		"func MustLoadStubCalculator(path string) *StubCalculator",
+		"R1 *string",
+		"r.R1 = errors.New(*v.R1)",
+		"s.DivideSeq = s.DivideSeq[1:]",
+		`at = fmt.Sprintf("%s[%d]", name, i)`,
+		`return fmt.Errorf("%s: method %s: field %s: %w", path, at, typeErr.Field, err)`,
+	} {
+		if !strings.Contains(string(src), c) {
+			t.Errorf("mockfile() should contain %q\n%s", c, src)
+		}
+	}
+}
+
+func TestMockfileFixtureGeneric(t *testing.T) {
+	pkg, intf := newFixtureTestInterface([]*model.TypeParameter{
+		model.NewTypeParameter("T", model.ConstraintAny, 0),
+	})
+
+	code := mockfile(pkg, intf, "", "", "", options{fixture: true}).PrintCode()
+	src, err := format.Source([]byte(code))
+	if err != nil {
+		t.Fatalf("mockfile() generated invalid code: %v\n%s", err, code)
+	}
+
+	for _, c := range []string{
+		"func LoadStubCalculator[T any](path string) (*StubCalculator[T], error)",
+		"s, err := LoadStubCalculator[T](path)",
+	} {
+		if !strings.Contains(string(src), c) {
+			t.Errorf("mockfile() should contain %q\n%s", c, src)
+		}
+	}
+}
+
+func TestStubSchema(t *testing.T) {
+	_, intf := newFixtureTestInterface(nil)
+
+	b, err := stubSchema(intf)
+	if err != nil {
+		t.Fatalf("stubSchema() error = %v", err)
+	}
+
+	var schema struct {
+		Title      string
+		Properties map[string]any
+		Defs       map[string]struct {
+			Properties map[string]map[string]any
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatalf("stubSchema() returned invalid json: %v", err)
+	}
+
+	if schema.Title != "StubCalculator" {
+		t.Errorf("title = %v, want %v", schema.Title, "StubCalculator")
+	}
+	if _, ok := schema.Properties["Divide"]; !ok {
+		t.Error("schema should have property for each method")
+	}
+	divide := schema.Defs["StubDivide"].Properties
+	if divide["R0"]["type"] != "integer" {
+		t.Errorf("R0 type = %v, want %v", divide["R0"]["type"], "integer")
+	}
+	if _, ok := divide["R1"]["type"].([]any); !ok {
+		t.Errorf("R1 type = %v, want string or null", divide["R1"]["type"])
+	}
+}
+
+func TestJSONTagName(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{`json:"name"`, "name"},
+		{`json:"name,omitempty"`, "name"},
+		{`xml:"x" json:"-"`, "-"},
+		{`xml:"x"`, ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := jsonTagName(tt.tag); got != tt.expected {
+				t.Errorf("jsonTagName(%v) = %v, want %v", tt.tag, got, tt.expected)
+			}
+		})
+	}
+}
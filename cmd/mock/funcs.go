@@ -0,0 +1,131 @@
+package mock
+
+import (
+	"fmt"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+const funcRcvName = "f"
+
+func getFuncName(intfName string) string {
+	return intfName + "Func"
+}
+
+func getFuncsName(intfName string) string {
+	return intfName + "Funcs"
+}
+
+func getFuncsFieldName(intfMethodName string) string {
+	return intfMethodName + "Func"
+}
+
+// funcfile returns the file which has the func adapter of targetIntf.
+// The adapter is the func type XxxFunc if targetIntf has exactly one method, like http.HandlerFunc,
+// or the struct XxxFuncs of func fields otherwise.
+func funcfile(targetPkg *model.Package, targetIntf *model.Interface, outFile, outPkgName, selfPkgPath string) (*model.File, error) {
+	if len(targetIntf.Methods()) == 0 {
+		return nil, fmt.Errorf("%s has no methods to adapt funcs to", targetIntf.Name())
+	}
+
+	// output
+	outPkgName, outPkgPath := gen.OutPackage(targetPkg, outFile, outPkgName, selfPkgPath)
+	outPkg := model.NewPkgInfo(outPkgName, outPkgPath, "")
+
+	// create file which has the adapter.
+	file := model.NewFile(outFile, outPkgName, outPkgPath, targetPkg.CopyDependencies())
+	file.DependenciesTidy()
+
+	intfType := targetIntf.Type()
+	if targetIntf.IsGeneric() {
+		intfType = model.NewGenericTypeNamed(targetIntf.Type().Pkg(), targetIntf.Name(), targetIntf.Type().Org(), targetIntf.TypeParams())
+	}
+
+	if len(targetIntf.Methods()) == 1 {
+		fn := funcAdapter(targetIntf, outPkg)
+		file.AddTypeDef(fn)
+		file.AddAssertion(model.NewAssertion(intfType, fn.Type(), false))
+	} else {
+		funcs := funcsAdapter(targetIntf, outPkg)
+		file.AddStruct(funcs)
+		file.AddAssertion(model.NewAssertion(intfType, funcs.Type(), false))
+	}
+
+	file.DependenciesTidy()
+	return file, nil
+}
+
+// funcAdapter returns the func type with the signature of the only method of targetIntf,
+// which implements targetIntf by calling itself.
+func funcAdapter(targetIntf *model.Interface, outPkg *model.PkgInfo) *model.TypeDef {
+	intfMethod := targetIntf.Methods()[0]
+	name := getFuncName(targetIntf.Name())
+	fn := model.NewTypeDef(name, outPkg, intfMethod.Type())
+	if targetIntf.IsGeneric() {
+		fn = model.NewGenericTypeDef(name, outPkg, intfMethod.Type(), targetIntf.TypeParams())
+	}
+
+	intfRef := gen.IntfRef(targetIntf, outPkg.Path())
+	fn.SetDoc(fmt.Sprintf("%s is an adapter to use an ordinary function as [%s].", name, intfRef))
+
+	/*
+		return f(a0, a1)
+	*/
+	method := model.NewMethod(
+		model.NewParameter(funcRcvName, fn.Type()),
+		intfMethod.Name(),
+		fmtSignature(intfMethod.Type()),
+		funcCall(funcRcvName, intfMethod.Type()),
+	)
+	method.SetDoc(fmt.Sprintf("%s implements [%s.%s] by calling f.", intfMethod.Name(), intfRef, intfMethod.Name()))
+	fn.AddMethod(method)
+	return fn
+}
+
+// funcsAdapter returns the struct with the func field for each method of targetIntf,
+// which implements targetIntf by calling the fields.
+// It is MockXxx without the embedded interface, so calling a method whose field is nil panics.
+func funcsAdapter(targetIntf *model.Interface, outPkg *model.PkgInfo) *model.Struct {
+	name := getFuncsName(targetIntf.Name())
+	funcs := model.NewStruct(name, outPkg)
+	if targetIntf.IsGeneric() {
+		funcs = model.NewGenericStruct(name, outPkg, targetIntf.TypeParams())
+	}
+
+	intfRef := gen.IntfRef(targetIntf, outPkg.Path())
+	funcs.SetDoc(fmt.Sprintf(`%s is an adapter to use ordinary functions as [%s].
+Each method calls the field of the same name with Func suffix, which must not be nil.`, name, intfRef))
+
+	/*
+		return f.GetFunc(a0, a1)
+	*/
+	rcv := model.NewParameter(funcRcvName, funcs.Type())
+	for _, intfMethod := range targetIntf.Methods() {
+		field := getFuncsFieldName(intfMethod.Name())
+		funcs.AddField(model.NewField(field, intfMethod.Type(), ""))
+
+		method := model.NewMethod(rcv, intfMethod.Name(), fmtSignature(intfMethod.Type()), funcCall(funcRcvName+"."+field, intfMethod.Type()))
+		method.SetDoc(fmt.Sprintf("%s implements [%s.%s] by calling %s.", intfMethod.Name(), intfRef, intfMethod.Name(), field))
+		funcs.AddMethod(method)
+	}
+	return funcs
+}
+
+// funcCall returns the statement calling fn with the parameters of the signature formatted by fmtSignature.
+func funcCall(fn string, sig *model.TypeSignature) string {
+	var s string
+	if len(sig.Results()) != 0 {
+		s += "return "
+	}
+	args := []interface{}{}
+	var n int
+	for range sig.Args() {
+		args = append(args, getMockArgsName(n))
+		n++
+	}
+	if sig.Variadic() != nil {
+		args = append(args, getMockArgsName(n)+"...")
+	}
+	return s + fn + fmt.Sprintf(sig.PrintCallArgsFmt(), args...)
+}
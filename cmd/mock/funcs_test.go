@@ -0,0 +1,111 @@
+package mock
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/cmd/internal/gen"
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestFuncfile(t *testing.T) {
+	pkg := &model.Package{
+		Name:         "bus",
+		Path:         "example.com/bus",
+		Dependencies: model.NewPackageMap("bus", "example.com/bus"),
+	}
+	busPkg := model.NewPkgInfo("bus", "example.com/bus", "")
+	msg := model.NewTypeNamed(busPkg, "Msg", model.NewTypeStruct(nil))
+	handle := model.NewFunc("Handle", model.NewTypeSignature(
+		[]*model.Parameter{model.NewParameter("m", msg)},
+		model.NewParameter("opts", model.NewTypeBasic("string")),
+		[]*model.Parameter{model.NewParameter("", gen.ErrorType)},
+	), "")
+	closing := model.NewFunc("Close", model.NewTypeSignature(nil, nil, nil), "")
+
+	tests := []struct {
+		name    string
+		intf    *model.Interface
+		expects []string
+	}{
+		{
+			name: "single method",
+			intf: model.NewInterface("Handler", busPkg, []*model.Func{handle}),
+			expects: []string{
+				"// HandlerFunc is an adapter to use an ordinary function as [Handler].\ntype HandlerFunc func(m Msg, opts ...string) error",
+				"// Handle implements [Handler.Handle] by calling f.\nfunc (f HandlerFunc) Handle(a0 Msg, a1 ...string) error {\n\treturn f(a0, a1...)\n}",
+				"var _ Handler = HandlerFunc(nil)",
+			},
+		},
+		{
+			name: "multiple methods",
+			intf: model.NewInterface("Consumer", busPkg, []*model.Func{closing, handle}),
+			expects: []string{
+				"type ConsumerFuncs struct {\n\tCloseFunc  func()\n\tHandleFunc func(m Msg, opts ...string) error\n}",
+				"func (f ConsumerFuncs) Close() {\n\tf.CloseFunc()\n}",
+				"// Handle implements [Consumer.Handle] by calling HandleFunc.\nfunc (f ConsumerFuncs) Handle(a0 Msg, a1 ...string) error {\n\treturn f.HandleFunc(a0, a1...)\n}",
+				"var _ Consumer = ConsumerFuncs{}",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := funcfile(pkg, tt.intf, "", "", "")
+			if err != nil {
+				t.Fatalf("funcfile() error = %v", err)
+			}
+			src, err := format.Source([]byte(file.PrintCode()))
+			if err != nil {
+				t.Fatalf("funcfile() generated invalid code: %v\n%s", err, file.PrintCode())
+			}
+			for _, expect := range tt.expects {
+				if !strings.Contains(string(src), expect) {
+					t.Errorf("funcfile() missing %q\n%s", expect, src)
+				}
+			}
+		})
+	}
+
+	_, err := funcfile(pkg, model.NewInterface("Empty", busPkg, nil), "", "", "")
+	if err == nil || err.Error() != "Empty has no methods to adapt funcs to" {
+		t.Errorf("funcfile() error = %v, want %v", err, "Empty has no methods to adapt funcs to")
+	}
+}
+
+func TestFuncfileGeneric(t *testing.T) {
+	pkg := &model.Package{
+		Name:         "store",
+		Path:         "example.com/store",
+		Dependencies: model.NewPackageMap("store", "example.com/store"),
+	}
+	k := model.NewTypeParameter("K", model.ConstraintComparable, 0)
+	v := model.NewTypeParameter("V", model.ConstraintAny, 1)
+	methods := []*model.Func{
+		model.NewFunc("Get", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("key", k)},
+			nil,
+			[]*model.Parameter{model.NewParameter("", v), model.NewParameter("", model.NewTypeBasic("bool"))},
+		), ""),
+	}
+	intf := model.NewGenericInterface("Getter", model.NewPkgInfo("store", "example.com/store", ""), methods, []*model.TypeParameter{k, v})
+
+	file, err := funcfile(pkg, intf, "", "", "")
+	if err != nil {
+		t.Fatalf("funcfile() error = %v", err)
+	}
+	src, err := format.Source([]byte(file.PrintCode()))
+	if err != nil {
+		t.Fatalf("funcfile() generated invalid code: %v", err)
+	}
+	expects := []string{
+		"type GetterFunc[K comparable, V any] func(key K) (V, bool)",
+		"func (f GetterFunc[K, V]) Get(a0 K) (V, bool) {\n\treturn f(a0)\n}",
+		"func _[K comparable, V any]() {\n\tvar _ Getter[K, V] = GetterFunc[K, V](nil)\n}",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(string(src), expect) {
+			t.Errorf("funcfile() missing %q\n%s", expect, src)
+		}
+	}
+}
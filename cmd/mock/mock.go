@@ -2,8 +2,10 @@ package mock
 
 import (
 	"flag"
+	"go/token"
 	"sort"
 
+	"github.com/kmio11/codegen/cmd/internal/gen"
 	"github.com/kmio11/codegen/generator"
 	"github.com/kmio11/codegen/generator/model"
 	"github.com/kmio11/codegen/generator/parser"
@@ -23,6 +25,13 @@ type Command struct {
 	flagOut         *string
 	flagOutPkg      *string
 	flagSelfPkgPath *string
+	flagRecord      *bool
+	flagDeepCopy    *bool
+	flagFixture     *bool
+	flagSchema      *string
+	flagUnexported  *string
+	flagKind        *string
+	flagErrDecl     *bool
 }
 
 func New() *Command {
@@ -33,6 +42,13 @@ func New() *Command {
 	c.flagOut = c.fs.String("out", "", "Output file; defaults to stdout.")
 	c.flagOutPkg = c.fs.String("outpkg", "", "Output package name; defaults to the same package specified by -pkg")
 	c.flagSelfPkgPath = c.fs.String("selfpkg", "", "The full package import path of the output package.")
+	c.flagRecord = c.fs.Bool("record", false, "Record the arguments of every call to the mock.")
+	c.flagDeepCopy = c.fs.Bool("deepcopy", false, "Deep-copy slices, maps and pointers, including recursive types, in recorded arguments; implies -record.")
+	c.flagFixture = c.fs.Bool("fixture", false, "Generate LoadStubXxx and MustLoadStubXxx which read stub results from a JSON file.")
+	c.flagSchema = c.fs.String("schema", "", "Output file of the JSON schema of the fixture read by LoadStubXxx.")
+	c.flagUnexported = c.fs.String("unexported", string(generator.UnexportedWarn), "How to handle methods referring to unexported types of other packages: warn, skip or error.")
+	c.flagKind = c.fs.String("kind", string(KindMock), "The kind of the output: mock (MockXxx and StubXxx), nop (NopXxx), unimplemented (UnimplementedXxx) or func (XxxFunc, or XxxFuncs for multiple methods).")
+	c.flagErrDecl = c.fs.Bool("errdecl", true, "Declare ErrUnimplemented with -kind unimplemented; set false if another file of the package declares it.")
 
 	return c
 }
@@ -47,7 +63,7 @@ func (c Command) Description() string {
 
 func (c Command) Usage(cmd string) {
 	fmt.Printf(`Usage:
-    %s %s -pkg <package> -type <type> [-out <out>] [-outpkg <outpkg> [-selfpkg <selfpkg>]]
+    %s %s -pkg <package> -type <type> [-out <out>] [-outpkg <outpkg> [-selfpkg <selfpkg>]] [-record [-deepcopy]] [-fixture [-schema <schema>]] [-unexported warn|skip|error] [-kind mock|nop|unimplemented|func [-errdecl=false]]
 
 `,
 		cmd, c.Name(),
@@ -66,6 +82,16 @@ func (c Command) Parse(args []string) error {
 	if len(*c.flagOutPkg) == 0 && len(*c.flagSelfPkgPath) != 0 {
 		return fmt.Errorf("")
 	}
+	if _, err := generator.ParseUnexportedMode(*c.flagUnexported); err != nil {
+		return fmt.Errorf("-unexported: %w", err)
+	}
+	kind, err := ParseKind(*c.flagKind)
+	if err != nil {
+		return fmt.Errorf("-kind: %w", err)
+	}
+	if kind != KindMock && (*c.flagRecord || *c.flagDeepCopy || *c.flagFixture || *c.flagSchema != "") {
+		return fmt.Errorf("-record, -deepcopy, -fixture and -schema can be used only with -kind %s", KindMock)
+	}
 
 	return nil
 }
@@ -78,14 +104,41 @@ func (c Command) Execute() int {
 		return 1
 	}
 
+	// methods referring to unexported types of other packages cannot be implemented.
+	mode, _ := generator.ParseUnexportedMode(*c.flagUnexported)
+	_, outPkgPath := gen.OutPackage(targetPkg, *c.flagOut, *c.flagOutPkg, *c.flagSelfPkgPath)
+	targetIntf, err = filterUnexported(targetPkg, targetIntf, outPkgPath, mode)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
 	// create mock
-	file := mockfile(targetPkg, targetIntf, *c.flagOut, *c.flagOutPkg, *c.flagSelfPkgPath)
+	kind, _ := ParseKind(*c.flagKind)
+	var file *model.File
+	switch kind {
+	case KindMock:
+		opts := options{
+			record:   *c.flagRecord || *c.flagDeepCopy,
+			deepCopy: *c.flagDeepCopy,
+			fixture:  *c.flagFixture,
+		}
+		file = mockfile(targetPkg, targetIntf, *c.flagOut, *c.flagOutPkg, *c.flagSelfPkgPath, opts)
+	case KindFunc:
+		file, err = funcfile(targetPkg, targetIntf, *c.flagOut, *c.flagOutPkg, *c.flagSelfPkgPath)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+	default:
+		file = basefile(targetPkg, targetIntf, *c.flagOut, *c.flagOutPkg, *c.flagSelfPkgPath, kind, *c.flagErrDecl)
+	}
 
 	// generate
 	g := &generator.Generator{}
 	src := g.
 		PrintHeader(c.Name()).
-		Printf("// Mock for %s.%s", targetPkg.Path, targetIntf.Name()).
+		Printf("// %s for %s.%s", kind.title(), targetPkg.Path, targetIntf.Name()).
 		NewLine().
 		Printf("%s", file.PrintCode()).
 		Format()
@@ -102,6 +155,21 @@ func (c Command) Execute() int {
 		fmt.Printf("File created successfully : %s\n", file.Path())
 	}
 
+	// schema of fixture
+	if *c.flagSchema != "" {
+		schema, err := stubSchema(targetIntf)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		err = os.WriteFile(*c.flagSchema, schema, 0644)
+		if err != nil {
+			log.Printf("writing schema: %s\n", err)
+			return 1
+		}
+		fmt.Printf("File created successfully : %s\n", *c.flagSchema)
+	}
+
 	return 0
 }
 
@@ -126,16 +194,61 @@ func parse(typ string, pkg string) (*model.Package, *model.Interface, error) {
 	return targetPkg, targetIntf, nil
 }
 
-func mockfile(targetPkg *model.Package, targetIntf *model.Interface, outFile, outPkgName, selfPkgPath string) *model.File {
-	// output
-	outPkgPath := selfPkgPath
-	if outPkgName != "" && outPkgPath == "" {
-		outPkgPath = outPkgName
+// Kind is the kind of the output.
+type Kind string
+
+// Kinds.
+const (
+	KindMock          Kind = "mock"          // MockXxx and StubXxx
+	KindNop           Kind = "nop"           // NopXxx returning zero values
+	KindUnimplemented Kind = "unimplemented" // UnimplementedXxx returning ErrUnimplemented
+	KindFunc          Kind = "func"          // XxxFunc, or XxxFuncs of func fields for multiple methods
+)
+
+// ParseKind returns Kind.
+func ParseKind(s string) (Kind, error) {
+	switch k := Kind(s); k {
+	case KindMock, KindNop, KindUnimplemented, KindFunc:
+		return k, nil
+	}
+	return "", fmt.Errorf("invalid kind %q: must be one of %s, %s, %s, %s", s, KindMock, KindNop, KindUnimplemented, KindFunc)
+}
+
+// title returns the word describing the output in the header.
+func (k Kind) title() string {
+	switch k {
+	case KindNop:
+		return "Nop"
+	case KindUnimplemented:
+		return "Unimplemented"
+	case KindFunc:
+		return "Func adapter"
 	}
-	if outPkgName == "" {
-		outPkgName = targetPkg.Name
-		outPkgPath = targetPkg.Path
+	return "Mock"
+}
+
+// filterUnexported handles the references from targetIntf to unexported types which cannot be referred from outPkgPath.
+// If the output package is not the target package, such as the external test package, they are errors whatever mode is.
+func filterUnexported(targetPkg *model.Package, targetIntf *model.Interface, outPkgPath string, mode generator.UnexportedMode) (*model.Interface, error) {
+	if outPkgPath == targetPkg.Path {
+		return generator.FilterUnexported(targetIntf, outPkgPath, mode, log.Default())
 	}
+	if !token.IsExported(targetIntf.Name()) {
+		return nil, fmt.Errorf("%s is unexported and cannot be referred from %s", targetIntf.Name(), outPkgPath)
+	}
+	return generator.FilterUnexported(targetIntf, outPkgPath, generator.UnexportedError, log.Default())
+}
+
+// options controls the optional parts of the generated mock.
+type options struct {
+	record   bool // record the arguments of every call
+	deepCopy bool // deep-copy recorded arguments
+	fixture  bool // load stub results from JSON file
+}
+
+func mockfile(targetPkg *model.Package, targetIntf *model.Interface, outFile, outPkgName, selfPkgPath string, opts options) *model.File {
+	// output
+	outPkgName, outPkgPath := gen.OutPackage(targetPkg, outFile, outPkgName, selfPkgPath)
 	outPkg := model.NewPkgInfo(outPkgName, outPkgPath, "")
 
 	// create file which has mock.
@@ -146,6 +259,13 @@ func mockfile(targetPkg *model.Package, targetIntf *model.Interface, outFile, ou
 	mockImpl := mockImpl(targetPkg, targetIntf, outPkg)
 	file.AddStruct(mockImpl)
 
+	// create call records
+	var calls []*model.Struct
+	if opts.record {
+		file.Dependencies().Add(syncPkg.Path(), *syncPkg)
+		calls = callRecords(targetIntf, outPkg, mockImpl)
+	}
+
 	// create stub
 	stubRoot, stubs := stub(targetPkg, targetIntf, outPkg, mockImpl)
 
@@ -153,8 +273,46 @@ func mockfile(targetPkg *model.Package, targetIntf *model.Interface, outFile, ou
 	for _, stub := range stubs {
 		file.AddStruct(stub)
 	}
+	for _, call := range calls {
+		file.AddStruct(call)
+	}
+
+	// create funcs deep-copying the recursive types
+	var dc *deepCopier
+	if opts.deepCopy {
+		dc = newDeepCopier(outPkg.Path())
+		for _, call := range calls {
+			for _, f := range call.Fields() {
+				for _, fn := range dc.addFuncs(f.Type(), nil) {
+					file.AddFunc(fn)
+				}
+			}
+		}
+	}
+
+	// create fixture loader
+	var load, mustLoad *model.Func
+	if opts.fixture {
+		stubSequences(targetIntf, stubRoot, stubs)
+		for _, pkg := range fixtureImports() {
+			file.AddImport(pkg)
+		}
+		load, mustLoad = stubLoaders(targetIntf, stubRoot)
+		file.AddFunc(load)
+		file.AddFunc(mustLoad)
+	}
 
 	file.DependenciesTidy()
+
+	if dc != nil {
+		dc.resolve(*file.Dependencies())
+	}
+	if opts.record {
+		recordCalls(targetIntf, outPkg, mockImpl, calls, *file.Dependencies(), dc)
+	}
+	if opts.fixture {
+		setStubLoaderStatements(targetIntf, outPkg, stubRoot, stubs, load, mustLoad, *file.Dependencies())
+	}
 	return file
 }
 
@@ -179,6 +337,18 @@ func getStubMethodName(intfMethodName string) string {
 	return getMockFieldName(intfMethodName)
 }
 
+func getStubRootName(intfName string) string {
+	return "Stub" + intfName
+}
+
+func getStubStructName(intfMethodName string) string {
+	return "Stub" + intfMethodName
+}
+
+func getStubResultFieldName(i int) string {
+	return "R" + strconv.Itoa(i)
+}
+
 // fmtSignature returns *mode.TypeSignature
 // param and results names replaced by no duplication names.
 func fmtSignature(org *model.TypeSignature) *model.TypeSignature {
@@ -207,9 +377,23 @@ func fmtSignature(org *model.TypeSignature) *model.TypeSignature {
 	)
 }
 
+// Struct returns the mock struct named mockName of targetIntf declared in outPkg.
+// It is used by the commands which generate mocks together with other code.
+// targetIntf may be an instantiated generic interface, such as Cache[string, int].
+func Struct(mockName string, targetIntf *model.Interface, outPkg *model.PkgInfo) *model.Struct {
+	targetPkg := &model.Package{
+		Name: targetIntf.Type().Pkg().Name(),
+		Path: targetIntf.Type().Pkg().Path(),
+	}
+	return namedMockImpl(mockName, targetPkg, targetIntf, outPkg)
+}
+
 func mockImpl(targetPkg *model.Package, targetIntf *model.Interface, outPkg *model.PkgInfo) *model.Struct {
+	return namedMockImpl("Mock"+targetIntf.Name(), targetPkg, targetIntf, outPkg)
+}
+
+func namedMockImpl(mockName string, targetPkg *model.Package, targetIntf *model.Interface, outPkg *model.PkgInfo) *model.Struct {
 	//mock struct
-	mockName := "Mock" + targetIntf.Name()
 	var mockImpl *model.Struct
 
 	// Handle generic interfaces
@@ -239,7 +423,7 @@ func mockImpl(targetPkg *model.Package, targetIntf *model.Interface, outPkg *mod
 			typeParamsWithoutConstraints,
 		)
 	} else {
-		interfaceType = model.NewTypeNamed(
+		interfaceType = model.NewInstantiatedTypeNamed(
 			model.NewPkgInfo(
 				targetPkg.Name,
 				targetPkg.Path,
@@ -247,6 +431,7 @@ func mockImpl(targetPkg *model.Package, targetIntf *model.Interface, outPkg *mod
 			),
 			targetIntf.Name(),
 			targetIntf.Type().Org(),
+			targetIntf.Type().TypeArgs(),
 		)
 	}
 
@@ -258,6 +443,9 @@ func mockImpl(targetPkg *model.Package, targetIntf *model.Interface, outPkg *mod
 		),
 	)
 
+	intfRef := gen.IntfRef(targetIntf, outPkg.Path())
+	mockImpl.SetDoc(fmt.Sprintf("%s is a test double for [%s].", mockName, intfRef))
+
 	for _, intfMethod := range targetIntf.Methods() {
 		// Mock's Fields: FakeFunction
 		fakeFuncName := getMockFieldName(intfMethod.Name())
@@ -321,20 +509,20 @@ func mockImpl(targetPkg *model.Package, targetIntf *model.Interface, outPkg *mod
 		methodBody := fmt.Sprintf(bodyCallFmt, bodyCallArgs...)
 
 		// add method
-		mockImpl.AddMethod(
-			model.NewMethod(
-				methodRcv,
-				intfMethod.Name(),
-				fmtSignature(intfMethod.Type()),
-				methodBody,
-			),
+		method := model.NewMethod(
+			methodRcv,
+			intfMethod.Name(),
+			fmtSignature(intfMethod.Type()),
+			methodBody,
 		)
+		method.SetDoc(fmt.Sprintf("%s implements [%s.%s] by calling %s.", intfMethod.Name(), intfRef, intfMethod.Name(), fakeFuncName))
+		mockImpl.AddMethod(method)
 	}
 	return mockImpl
 }
 
 func stub(targetPkg *model.Package, targetIntf *model.Interface, outPkg *model.PkgInfo, mockImpl *model.Struct) (stubRoot *model.Struct, stubs []*model.Struct) {
-	stubRootName := "Stub" + targetIntf.Name()
+	stubRootName := getStubRootName(targetIntf.Name())
 
 	// Handle generic interfaces for stub root
 	if targetIntf.IsGeneric() {
@@ -364,7 +552,7 @@ func stub(targetPkg *model.Package, targetIntf *model.Interface, outPkg *model.P
 	stubs = []*model.Struct{}
 	for _, intfMethod := range targetIntf.Methods() {
 		// stub for each intf's method.
-		stubName := "Stub" + intfMethod.Name()
+		stubName := getStubStructName(intfMethod.Name())
 		var stub *model.Struct
 
 		// Handle generic interfaces for individual stub structs
@@ -376,7 +564,7 @@ func stub(targetPkg *model.Package, targetIntf *model.Interface, outPkg *model.P
 		for i, param := range intfMethod.Type().Results() {
 			stub.AddField(
 				model.NewField(
-					"R"+strconv.Itoa(i),
+					getStubResultFieldName(i),
 					param.Type(),
 					"",
 				),
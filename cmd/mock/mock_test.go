@@ -1,8 +1,10 @@
 package mock
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/kmio11/codegen/generator"
 	"github.com/kmio11/codegen/generator/model"
 )
 
@@ -48,6 +50,41 @@ func TestCommandParse(t *testing.T) {
 			args:      []string{},
 			expectErr: true,
 		},
+		{
+			name:      "unexported skip",
+			args:      []string{"-type", "TestInterface", "-unexported", "skip"},
+			expectErr: false,
+		},
+		{
+			name:      "nop",
+			args:      []string{"-type", "TestInterface", "-kind", "nop"},
+			expectErr: false,
+		},
+		{
+			name:      "unimplemented without errdecl",
+			args:      []string{"-type", "TestInterface", "-kind", "unimplemented", "-errdecl=false"},
+			expectErr: false,
+		},
+		{
+			name:      "func",
+			args:      []string{"-type", "TestInterface", "-kind", "func"},
+			expectErr: false,
+		},
+		{
+			name:      "invalid kind",
+			args:      []string{"-type", "TestInterface", "-kind", "fake"},
+			expectErr: true,
+		},
+		{
+			name:      "record with nop",
+			args:      []string{"-type", "TestInterface", "-kind", "nop", "-record"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid unexported",
+			args:      []string{"-type", "TestInterface", "-unexported", "ignore"},
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -276,3 +313,126 @@ func TestMockImplGeneric(t *testing.T) {
 		t.Errorf("mockImpl() type params count = %v, want %v", len(mockStruct.TypeParams()), 1)
 	}
 }
+
+func TestMockImplDoc(t *testing.T) {
+	pkg := &model.Package{
+		Name: "store",
+		Path: "example.com/store",
+	}
+	methods := []*model.Func{
+		model.NewFunc("Get", model.NewTypeSignature(nil, nil, nil), ""),
+	}
+	intf := model.NewInterface("Store", model.NewPkgInfo("store", "example.com/store", ""), methods)
+
+	tests := []struct {
+		name        string
+		outPkg      *model.PkgInfo
+		expectedDoc string
+		expectedRef string
+	}{
+		{
+			name:        "same package",
+			outPkg:      model.NewPkgInfo("store", "example.com/store", ""),
+			expectedDoc: "MockStore is a test double for [Store].",
+			expectedRef: "Get implements [Store.Get] by calling FakeGet.",
+		},
+		{
+			name:        "other package",
+			outPkg:      model.NewPkgInfo("mocks", "example.com/mocks", ""),
+			expectedDoc: "MockStore is a test double for [store.Store].",
+			expectedRef: "Get implements [store.Store.Get] by calling FakeGet.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStruct := mockImpl(pkg, intf, tt.outPkg)
+			if mockStruct.Doc() != tt.expectedDoc {
+				t.Errorf("mockImpl() doc = %v, want %v", mockStruct.Doc(), tt.expectedDoc)
+			}
+			if got := mockStruct.Methods()[0].Doc(); got != tt.expectedRef {
+				t.Errorf("mockImpl() method doc = %v, want %v", got, tt.expectedRef)
+			}
+		})
+	}
+}
+
+func TestMockfileExternalTest(t *testing.T) {
+	pkg := &model.Package{
+		Name:         "calc",
+		Path:         "example.com/calc",
+		Dependencies: model.NewPackageMap("calc", "example.com/calc"),
+	}
+	pkgInfo := model.NewPkgInfo(pkg.Name, pkg.Path, "")
+	item := model.NewTypeNamed(pkgInfo, "Item", model.NewTypeStruct(nil))
+	methods := []*model.Func{
+		model.NewFunc("Get", model.NewTypeSignature(
+			nil,
+			nil,
+			[]*model.Parameter{model.NewParameter("", model.NewPointer(item))},
+		), ""),
+	}
+	intf := model.NewInterface("Calculator", pkgInfo, methods)
+
+	file := mockfile(pkg, intf, "calc_mock_test.go", "calc_test", "", options{})
+	if file.Pkg().Path() != "example.com/calc_test" {
+		t.Errorf("package path = %v, want %v", file.Pkg().Path(), "example.com/calc_test")
+	}
+
+	code := file.PrintCode()
+	for _, c := range []string{
+		"package calc_test",
+		`"example.com/calc"`,
+		"calc.Calculator",
+		"func() *calc.Item",
+	} {
+		if !strings.Contains(code, c) {
+			t.Errorf("mockfile() should contain %q\n%s", c, code)
+		}
+	}
+}
+
+func TestFilterUnexported(t *testing.T) {
+	pkg := &model.Package{
+		Name:         "calc",
+		Path:         "example.com/calc",
+		Dependencies: model.NewPackageMap("calc", "example.com/calc"),
+	}
+	pkgInfo := model.NewPkgInfo(pkg.Name, pkg.Path, "")
+	key := model.NewTypeNamed(pkgInfo, "key", model.NewTypeBasic("string"))
+	newIntf := func(name string) *model.Interface {
+		return model.NewInterface(name, pkgInfo, []*model.Func{
+			model.NewFunc("Get", model.NewTypeSignature(
+				[]*model.Parameter{model.NewParameter("k", key)},
+				nil,
+				[]*model.Parameter{model.NewParameter("", model.NewTypeBasic("int"))},
+			), ""),
+			model.NewFunc("Len", model.NewTypeSignature(nil, nil, []*model.Parameter{model.NewParameter("", model.NewTypeBasic("int"))}), ""),
+		})
+	}
+
+	tests := []struct {
+		name       string
+		intf       string
+		outPkgPath string
+		mode       generator.UnexportedMode
+		expectErr  bool
+		methods    int
+	}{
+		{"same package", "Store", "example.com/calc", generator.UnexportedWarn, false, 2},
+		{"external test with warn", "Store", "example.com/calc_test", generator.UnexportedWarn, true, 0},
+		{"external test with skip", "Store", "example.com/calc_test", generator.UnexportedSkip, true, 0},
+		{"unexported interface", "store", "example.com/calc_test", generator.UnexportedWarn, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterUnexported(pkg, newIntf(tt.intf), tt.outPkgPath, tt.mode)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("filterUnexported() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err == nil && len(got.Methods()) != tt.methods {
+				t.Errorf("filterUnexported() methods = %d, want %d", len(got.Methods()), tt.methods)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package mock
+
+import (
+	"strconv"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+var syncPkg = model.NewPkgInfo("sync", "sync", "")
+
+const (
+	recordVarName   = "c"
+	recordMutexName = "mu"
+)
+
+func getCallFieldName(intfMethodName string) string {
+	return "Calls" + intfMethodName
+}
+
+func getCallStructName(intfMethodName string) string {
+	return "Call" + intfMethodName
+}
+
+func getCallArgsFieldName(i int) string {
+	return "A" + strconv.Itoa(i)
+}
+
+// callRecords adds fields recording calls to mockImpl,
+// and returns structs that hold the arguments of each call.
+func callRecords(targetIntf *model.Interface, outPkg *model.PkgInfo, mockImpl *model.Struct) []*model.Struct {
+	mockImpl.AddField(
+		model.NewField(
+			recordMutexName,
+			model.NewTypeNamed(syncPkg, "Mutex", model.NewTypeStruct([]*model.Field{})),
+			"",
+		),
+	)
+
+	calls := []*model.Struct{}
+	for _, intfMethod := range targetIntf.Methods() {
+		callName := getCallStructName(intfMethod.Name())
+		var call *model.Struct
+		if targetIntf.IsGeneric() {
+			call = model.NewGenericStruct(callName, outPkg, targetIntf.TypeParams())
+		} else {
+			call = model.NewStruct(callName, outPkg)
+		}
+
+		var n int
+		for _, param := range intfMethod.Type().Args() {
+			call.AddField(model.NewField(getCallArgsFieldName(n), param.Type(), ""))
+			n++
+		}
+		if v := intfMethod.Type().Variadic(); v != nil {
+			call.AddField(model.NewField(getCallArgsFieldName(n), model.NewTypeArray(-1, v.Type()), ""))
+		}
+		calls = append(calls, call)
+
+		mockImpl.AddField(
+			model.NewField(
+				getCallFieldName(intfMethod.Name()),
+				model.NewTypeArray(-1, call.Type()),
+				"",
+			),
+		)
+	}
+	return calls
+}
+
+// recordCalls prepends statements recording the arguments to each method of mockImpl.
+// If dc is not nil, the recorded arguments do not share memory with the passed ones.
+func recordCalls(targetIntf *model.Interface, outPkg *model.PkgInfo, mockImpl *model.Struct, calls []*model.Struct, pm model.PackageMap, dc *deepCopier) {
+	/*
+		c := CallXxx{A0: a0, A1: a1}
+		m.mu.Lock()
+		m.CallsXxx = append(m.CallsXxx, c)
+		m.mu.Unlock()
+	*/
+	methods := mockImpl.Methods()
+	for i, intfMethod := range targetIntf.Methods() {
+		call := calls[i]
+		method := methods[i]
+
+		s := recordVarName + " := " + call.Type().PrintType(outPkg.Path(), pm) + "{"
+		for j, f := range call.Fields() {
+			if j > 0 {
+				s += ", "
+			}
+			s += f.Name() + ": " + getMockArgsName(j)
+		}
+		s += "}\n"
+
+		if dc != nil {
+			for _, f := range call.Fields() {
+				s += dc.copyStmts(recordVarName+"."+f.Name(), f.Type(), nil, 0)
+			}
+		}
+
+		callField := mockRcvName + "." + getCallFieldName(intfMethod.Name())
+		s += mockRcvName + "." + recordMutexName + ".Lock()\n"
+		s += callField + " = append(" + callField + ", " + recordVarName + ")\n"
+		s += mockRcvName + "." + recordMutexName + ".Unlock()\n"
+
+		method.SetStatements(s + method.Statements())
+	}
+}
@@ -0,0 +1,131 @@
+package mock
+
+import (
+	"encoding/json"
+	"go/token"
+	"reflect"
+	"strings"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+const jsonSchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// stubSchema returns JSON schema of the fixture read by LoadStubXxx.
+func stubSchema(targetIntf *model.Interface) ([]byte, error) {
+	defs := map[string]any{}
+	props := map[string]any{}
+	for _, intfMethod := range targetIntf.Methods() {
+		fields := []*model.Field{}
+		for i, r := range intfMethod.Type().Results() {
+			fields = append(fields, model.NewField(getStubResultFieldName(i), r.Type(), ""))
+		}
+		stubName := getStubStructName(intfMethod.Name())
+		defs[stubName] = schemaOf(model.NewTypeStruct(fields), map[*model.TypeNamed]bool{})
+
+		ref := map[string]any{"$ref": "#/$defs/" + stubName}
+		props[intfMethod.Name()] = map[string]any{
+			"anyOf": []any{
+				ref,
+				map[string]any{"type": "array", "items": ref},
+			},
+		}
+	}
+
+	schema := map[string]any{
+		"$schema":              jsonSchemaVersion,
+		"title":                getStubRootName(targetIntf.Name()),
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+		"$defs":                defs,
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaOf returns JSON schema of the value encoding/json decodes into typ.
+func schemaOf(typ model.Type, visiting map[*model.TypeNamed]bool) map[string]any {
+	nullable := func(s map[string]any) map[string]any {
+		return map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+	}
+
+	switch t := typ.(type) {
+	case *model.TypeBasic:
+		switch name := string(*t); {
+		case name == "bool":
+			return map[string]any{"type": "boolean"}
+		case name == "string":
+			return map[string]any{"type": "string"}
+		case strings.HasPrefix(name, "int"), strings.HasPrefix(name, "uint"), name == "byte", name == "rune":
+			return map[string]any{"type": "integer"}
+		case strings.HasPrefix(name, "float"):
+			return map[string]any{"type": "number"}
+		default:
+			return map[string]any{}
+		}
+
+	case *model.TypeArray:
+		if b, ok := t.Type().(*model.TypeBasic); ok && t.Len() < 0 && (*b == "byte" || *b == "uint8") {
+			// []byte is encoded as base64 string.
+			return nullable(map[string]any{"type": "string", "contentEncoding": "base64"})
+		}
+		s := map[string]any{"type": "array", "items": schemaOf(t.Type(), visiting)}
+		if t.Len() >= 0 {
+			s["minItems"] = t.Len()
+			s["maxItems"] = t.Len()
+			return s
+		}
+		return nullable(s)
+
+	case *model.TypeMap:
+		return nullable(map[string]any{"type": "object", "additionalProperties": schemaOf(t.Value(), visiting)})
+
+	case *model.TypePointer:
+		return nullable(schemaOf(t.Type(), visiting))
+
+	case *model.TypeStruct:
+		props := map[string]any{}
+		for _, f := range t.Fields() {
+			name := f.Name()
+			if name == "" || !token.IsExported(name) {
+				continue
+			}
+			if tag := jsonTagName(f.Tag()); tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+			props[name] = schemaOf(f.Type(), visiting)
+		}
+		return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+
+	case *model.TypeNamed:
+		if isErrorType(t) {
+			return map[string]any{"type": []string{"string", "null"}}
+		}
+		if t.Pkg() != nil && t.Pkg().Path() == "time" {
+			switch t.Name() {
+			case "Time":
+				return map[string]any{"type": "string", "format": "date-time"}
+			case "Duration":
+				return map[string]any{"type": "integer"}
+			}
+		}
+		if visiting[t] {
+			return map[string]any{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		return schemaOf(t.Org(), visiting)
+
+	default:
+		// interfaces, type parameters and others accept any value.
+		return map[string]any{}
+	}
+}
+
+// jsonTagName returns the name in json struct tag.
+func jsonTagName(tag string) string {
+	name, _, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+	return name
+}
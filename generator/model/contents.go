@@ -47,6 +47,7 @@ type Contents interface {
 // Interface is interface.
 type Interface struct {
 	typ *TypeNamed
+	doc string
 }
 
 // NewInterface returns Interface.
@@ -75,6 +76,20 @@ func NewGenericInterface(name string, pkg *PkgInfo, methods []*Func, typeParams
 	}
 }
 
+// NewInstantiatedInterface returns Interface of the generic interface instantiated with type arguments.
+// e.g. Cache[string, int]
+func NewInstantiatedInterface(name string, pkg *PkgInfo, methods []*Func, typeArgs []Type, embeddeds ...*TypeNamed) *Interface {
+	return &Interface{
+		typ: NewInstantiatedTypeNamed(pkg, name,
+			NewTypeInterface(
+				embeddeds,
+				methods,
+			),
+			typeArgs,
+		),
+	}
+}
+
 // Name returns name.
 func (i *Interface) Name() string {
 	return i.typ.Name()
@@ -100,6 +115,16 @@ func (i *Interface) IsGeneric() bool {
 	return i.typ.IsGeneric()
 }
 
+// Doc returns the doc comment.
+func (i *Interface) Doc() string {
+	return i.doc
+}
+
+// SetDoc set the doc comment, the text without comment markers.
+func (i *Interface) SetDoc(doc string) {
+	i.doc = doc
+}
+
 func (i Interface) addImports(pm *PackageMap) {
 	i.typ.addImports(pm)
 	i.typ.Org().addImports(pm)
@@ -112,13 +137,14 @@ func (i *Interface) PrintCode(myPkgPath string, pm PackageMap) string {
 			GetXXX (x int, y int) int
 		}
 	*/
-	return i.typ.PrintTypeDef(myPkgPath, pm)
+	return printDoc(i.doc) + i.typ.PrintTypeDef(myPkgPath, pm)
 }
 
 // Struct is struct.
 type Struct struct {
 	typ     *TypeNamed
 	methods []*Method
+	doc     string
 }
 
 // NewStruct return Struct
@@ -172,6 +198,16 @@ func (s *Struct) IsGeneric() bool {
 	return s.typ.IsGeneric()
 }
 
+// Doc returns the doc comment.
+func (s *Struct) Doc() string {
+	return s.doc
+}
+
+// SetDoc set the doc comment, the text without comment markers.
+func (s *Struct) SetDoc(doc string) {
+	s.doc = doc
+}
+
 func (s *Struct) addImports(pm *PackageMap) {
 	s.typ.addImports(pm)
 	s.TypeStruct().addImports(pm)
@@ -192,7 +228,7 @@ func (s *Struct) PrintCode(myPkgPath string, pm PackageMap) string {
 			return 1
 		}
 	*/
-	str := s.typ.PrintTypeDef(myPkgPath, pm)
+	str := printDoc(s.doc) + s.typ.PrintTypeDef(myPkgPath, pm)
 
 	// methods
 	str += "\n"
@@ -213,6 +249,87 @@ func (s *Struct) AddMethod(m *Method) {
 	s.methods = append(s.methods, m)
 }
 
+// TypeDef is the definition of the named type other than struct and interface, such as func type.
+type TypeDef struct {
+	typ     *TypeNamed
+	methods []*Method
+	doc     string
+}
+
+// NewTypeDef returns TypeDef defining name as org.
+func NewTypeDef(name string, pkg *PkgInfo, org Type) *TypeDef {
+	return &TypeDef{
+		typ:     NewTypeNamed(pkg, name, org),
+		methods: []*Method{},
+	}
+}
+
+// NewGenericTypeDef returns generic TypeDef with type parameters.
+func NewGenericTypeDef(name string, pkg *PkgInfo, org Type, typeParams []*TypeParameter) *TypeDef {
+	return &TypeDef{
+		typ:     NewGenericTypeNamed(pkg, name, org, typeParams),
+		methods: []*Method{},
+	}
+}
+
+// Name returns name.
+func (d *TypeDef) Name() string {
+	return d.typ.Name()
+}
+
+// Type returns named type.
+func (d *TypeDef) Type() *TypeNamed {
+	return d.typ
+}
+
+// Methods returns methods.
+func (d *TypeDef) Methods() []*Method {
+	return d.methods
+}
+
+// AddMethod add method to the type.
+func (d *TypeDef) AddMethod(m *Method) {
+	d.methods = append(d.methods, m)
+}
+
+// Doc returns the doc comment.
+func (d *TypeDef) Doc() string {
+	return d.doc
+}
+
+// SetDoc set the doc comment, the text without comment markers.
+func (d *TypeDef) SetDoc(doc string) {
+	d.doc = doc
+}
+
+func (d *TypeDef) addImports(pm *PackageMap) {
+	d.typ.addImports(pm)
+	d.typ.Org().addImports(pm)
+
+	for _, m := range d.methods {
+		m.addImports(pm)
+	}
+}
+
+// PrintCode print code.
+func (d *TypeDef) PrintCode(myPkgPath string, pm PackageMap) string {
+	/*
+		type HandlerFunc func(inv *Invocation)
+
+		func (f HandlerFunc) Handle(inv *Invocation) {
+			f(inv)
+		}
+	*/
+	str := printDoc(d.doc) + d.typ.PrintTypeDef(myPkgPath, pm) + "\n"
+
+	// methods
+	for _, m := range d.methods {
+		str += "\n"
+		str += m.PrintCode(myPkgPath, pm)
+	}
+	return str
+}
+
 // Field is field of struct.
 // If Parameter.Name is brank, it represents embeded field.
 type Field struct {
@@ -276,15 +393,18 @@ func (m *Method) PrintCode(myPkgPath string, pm PackageMap) string {
 			return x+i , nil
 		}
 	*/
-	s := "func "
+	s := printDoc(m.doc)
+	s += "func "
 	s += fmt.Sprintf("(%s)", m.rcv.PrintNameAndType(myPkgPath, pm))
 	s += m.name
 	s += m.typ.printArgs(myPkgPath, pm)
 	s += m.typ.printResults(myPkgPath, pm)
 	s += "{\n"
 
-	s += m.statements
-	s += "\n"
+	if m.statements != "" {
+		s += m.statements
+		s += "\n"
+	}
 
 	s += "}\n"
 	return s
@@ -295,6 +415,9 @@ type Func struct {
 	name       string
 	typ        *TypeSignature
 	statements string
+	typeParams []*TypeParameter
+	doc        string
+	pkg        *PkgInfo // package declaring the existing func
 }
 
 // NewFunc returns Func.
@@ -306,6 +429,25 @@ func NewFunc(name string, typ *TypeSignature, statements string) *Func {
 	}
 }
 
+// NewGenericFunc returns generic Func with type parameters.
+func NewGenericFunc(name string, typ *TypeSignature, statements string, typeParams []*TypeParameter) *Func {
+	return &Func{
+		name:       name,
+		typ:        typ,
+		statements: statements,
+		typeParams: typeParams,
+	}
+}
+
+// NewPackageFunc returns Func which refers to the existing package-level func declared in pkg.
+func NewPackageFunc(pkg *PkgInfo, name string, typ *TypeSignature) *Func {
+	return &Func{
+		name: name,
+		typ:  typ,
+		pkg:  pkg,
+	}
+}
+
 // Name returns name.
 func (f *Func) Name() string {
 	return f.name
@@ -321,6 +463,54 @@ func (f *Func) Statements() string {
 	return f.statements
 }
 
+// TypeParams returns type parameters.
+func (f *Func) TypeParams() []*TypeParameter {
+	return f.typeParams
+}
+
+// IsGeneric returns true if this func has type parameters.
+func (f *Func) IsGeneric() bool {
+	return len(f.typeParams) > 0
+}
+
+// Doc returns the doc comment.
+func (f *Func) Doc() string {
+	return f.doc
+}
+
+// SetDoc set the doc comment, the text without comment markers.
+func (f *Func) SetDoc(doc string) {
+	f.doc = doc
+}
+
+// Pkg returns the package declaring the func, or nil if the func is not an existing one.
+func (f *Func) Pkg() *PkgInfo {
+	return f.pkg
+}
+
+// PrintRef print the reference to the func.
+func (f *Func) PrintRef(myPkgPath string, pm PackageMap) string {
+	/*
+		os.ReadFile
+	*/
+	if f.pkg == nil {
+		return f.name
+	}
+	pkg := pm.Get(f.pkg.Path())
+	if pkg == nil {
+		pkg = f.pkg
+	}
+	return pkg.Prefix(myPkgPath) + f.name
+}
+
+// PrintDoc print the doc comment lines.
+func (f *Func) PrintDoc() string {
+	/*
+		// Func does something.
+	*/
+	return printDoc(f.doc)
+}
+
 // PrintDef print Name and Params and Results
 func (f *Func) PrintDef(myPkgPath string, pm PackageMap) string {
 	/*
@@ -336,14 +526,30 @@ func (f *Func) PrintCode(myPkgPath string, pm PackageMap) string {
 			return x+i , nil
 		}
 	*/
-	s := "func "
+	s := printDoc(f.doc)
+	s += "func "
 	s += f.name
+	if len(f.typeParams) > 0 {
+		s += "["
+		for i, param := range f.typeParams {
+			if i > 0 {
+				s += ", "
+			}
+			s += param.name
+			if param.constraint != nil {
+				s += " " + param.constraint.PrintType(myPkgPath, pm)
+			}
+		}
+		s += "]"
+	}
 	s += f.typ.printArgs(myPkgPath, pm)
 	s += f.typ.printResults(myPkgPath, pm)
 	s += "{\n"
 
-	s += f.statements
-	s += "\n"
+	if f.statements != "" {
+		s += f.statements
+		s += "\n"
+	}
 
 	s += "}\n"
 	return s
@@ -351,9 +557,121 @@ func (f *Func) PrintCode(myPkgPath string, pm PackageMap) string {
 
 func (f *Func) addImports(pm *PackageMap) {
 	f.typ.addImports(pm)
+	for _, param := range f.typeParams {
+		param.addImports(pm)
+	}
 }
 
 // SetStatements set statements.
 func (f *Func) SetStatements(statements string) {
 	f.statements = statements
 }
+
+// Var is a package-level variable.
+type Var struct {
+	name  string
+	typ   Type // nil if the type is inferred from value
+	value string
+	doc   string
+}
+
+// NewVar returns Var initialized by value.
+func NewVar(name string, typ Type, value string) *Var {
+	return &Var{
+		name:  name,
+		typ:   typ,
+		value: value,
+	}
+}
+
+// Name returns name.
+func (v *Var) Name() string {
+	return v.name
+}
+
+// Doc returns the doc comment.
+func (v *Var) Doc() string {
+	return v.doc
+}
+
+// SetDoc set the doc comment, the text without comment markers.
+func (v *Var) SetDoc(doc string) {
+	v.doc = doc
+}
+
+// SetValue set the expression of the initial value.
+func (v *Var) SetValue(value string) {
+	v.value = value
+}
+
+func (v *Var) addImports(pm *PackageMap) {
+	if v.typ != nil {
+		v.typ.addImports(pm)
+	}
+}
+
+// PrintCode print code.
+func (v *Var) PrintCode(myPkgPath string, pm PackageMap) string {
+	/*
+		var ErrNotFound = errors.New("not found")
+	*/
+	s := printDoc(v.doc) + "var " + v.name
+	if v.typ != nil {
+		s += " " + v.typ.PrintType(myPkgPath, pm)
+	}
+	return s + " = " + v.value + "\n"
+}
+
+// Assertion is a compile-time assertion that the type implements the interface.
+type Assertion struct {
+	intf    *TypeNamed
+	impl    *TypeNamed
+	pointer bool
+}
+
+// NewAssertion returns Assertion.
+// If pointer is true, it asserts that the pointer of impl implements intf.
+// If intf is generic, impl must have the same type parameters.
+func NewAssertion(intf *TypeNamed, impl *TypeNamed, pointer bool) *Assertion {
+	return &Assertion{
+		intf:    intf,
+		impl:    impl,
+		pointer: pointer,
+	}
+}
+
+func (a *Assertion) addImports(pm *PackageMap) {
+	a.intf.addImports(pm)
+	a.impl.addImports(pm)
+}
+
+// PrintCode print code.
+func (a *Assertion) PrintCode(myPkgPath string, pm PackageMap) string {
+	/*
+		var _ Foo = (*Impl)(nil)
+		var _ Foo = ImplFunc(nil)
+
+		func _[T any]() {
+			var _ Foo[T] = Impl[T]{}
+		}
+	*/
+	value := a.impl.PrintType(myPkgPath, pm) + "{}"
+	switch org := a.impl.Org().(type) {
+	case *TypeSignature, *TypeMap, *TypeChan, *TypePointer, *TypeInterface:
+		value = a.impl.PrintType(myPkgPath, pm) + "(nil)"
+	case *TypeArray:
+		if org.Len() < 0 {
+			value = a.impl.PrintType(myPkgPath, pm) + "(nil)"
+		}
+	}
+	if a.pointer {
+		value = fmt.Sprintf("(*%s)(nil)", a.impl.PrintType(myPkgPath, pm))
+	}
+	stmt := fmt.Sprintf("var _ %s = %s", a.intf.PrintType(myPkgPath, pm), value)
+
+	// type parameters can be used only in generic func.
+	if a.intf.IsGeneric() {
+		return NewGenericFunc("_", NewTypeSignature(nil, nil, nil), stmt, a.intf.TypeParams()).PrintCode(myPkgPath, pm)
+	}
+	return stmt + "\n"
+}
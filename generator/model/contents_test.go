@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -49,6 +50,12 @@ func TestGenericInterface(t *testing.T) {
 	if len(genericIntf.Methods()) != 2 {
 		t.Errorf("Methods() length = %v, want %v", len(genericIntf.Methods()), 2)
 	}
+
+	code := genericIntf.PrintCode("example.com/testpkg", PackageMap{})
+	expected := "type Repository[T any] interface{Get(id string)( T, error);Save(item T) error}"
+	if code != expected {
+		t.Errorf("PrintCode() = %v, want %v", code, expected)
+	}
 }
 
 func TestGenericStruct(t *testing.T) {
@@ -130,3 +137,177 @@ func TestNonGenericStruct(t *testing.T) {
 		t.Errorf("Name() = %v, want %v", nonGenericStruct.Name(), "SimpleStruct")
 	}
 }
+
+func TestGenericFunc(t *testing.T) {
+	typeParams := []*TypeParameter{
+		NewTypeParameter("K", ConstraintComparable, 0),
+		NewTypeParameter("V", ConstraintAny, 1),
+	}
+	fn := NewGenericFunc("Load", NewTypeSignature(
+		[]*Parameter{NewParameter("key", NewTypeParameter("K", nil, 0))},
+		nil,
+		[]*Parameter{NewParameter("", NewTypeParameter("V", nil, 1))},
+	), "panic(key)", typeParams)
+
+	if !fn.IsGeneric() {
+		t.Error("IsGeneric() should return true for generic func")
+	}
+
+	code := fn.PrintCode("", PackageMap{})
+	expected := "func Load[K comparable, V any](key K) V{"
+	if !strings.HasPrefix(code, expected) {
+		t.Errorf("PrintCode() = %v, want prefix %v", code, expected)
+	}
+}
+
+func TestFileAddImport(t *testing.T) {
+	file := NewFile("", "testpkg", "example.com/testpkg", NewPackageMap("testpkg", "example.com/testpkg"))
+	file.AddImport(NewPkgInfo("json", "encoding/json", ""))
+	file.AddFunc(NewFunc("Decode", NewTypeSignature(nil, nil, nil), "json.Valid(nil)"))
+
+	file.DependenciesTidy()
+	code := file.PrintCode()
+
+	if !strings.Contains(code, `"encoding/json"`) {
+		t.Errorf("PrintCode() should import packages added by AddImport\n%s", code)
+	}
+	if !strings.Contains(code, "func Decode()") {
+		t.Errorf("PrintCode() should contain func added by AddFunc\n%s", code)
+	}
+}
+
+func TestAssertion(t *testing.T) {
+	pkg := NewPkgInfo("testpkg", "example.com/testpkg", "")
+	typeParams := []*TypeParameter{NewTypeParameter("T", ConstraintAny, 0)}
+
+	tests := []struct {
+		name     string
+		intf     *TypeNamed
+		impl     *TypeNamed
+		pointer  bool
+		expected string
+	}{
+		{
+			name:     "value",
+			intf:     NewTypeNamed(pkg, "Amounter", NewTypeInterface(nil, nil)),
+			impl:     NewTypeNamed(pkg, "Money", NewTypeStruct(nil)),
+			expected: "var _ Amounter = Money{}\n",
+		},
+		{
+			name:     "pointer",
+			intf:     NewTypeNamed(pkg, "Adder", NewTypeInterface(nil, nil)),
+			impl:     NewTypeNamed(pkg, "Money", NewTypeStruct(nil)),
+			pointer:  true,
+			expected: "var _ Adder = (*Money)(nil)\n",
+		},
+		{
+			name:     "func",
+			intf:     NewTypeNamed(pkg, "Handler", NewTypeInterface(nil, nil)),
+			impl:     NewTypeNamed(pkg, "HandlerFunc", NewTypeSignature(nil, nil, nil)),
+			expected: "var _ Handler = HandlerFunc(nil)\n",
+		},
+		{
+			name:     "generic",
+			intf:     NewGenericTypeNamed(pkg, "Getter", NewTypeInterface(nil, nil), typeParams),
+			impl:     NewGenericTypeNamed(pkg, "Box", NewTypeStruct(nil), typeParams),
+			pointer:  true,
+			expected: "func _[T any](){\nvar _ Getter[T] = (*Box[T])(nil)\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewAssertion(tt.intf, tt.impl, tt.pointer).PrintCode("example.com/testpkg", PackageMap{})
+			if got != tt.expected {
+				t.Errorf("PrintCode() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrintDoc(t *testing.T) {
+	pkg := NewPkgInfo("testpkg", "example.com/testpkg", "")
+
+	get := NewFunc("Get", NewTypeSignature(nil, nil, nil), "")
+	get.SetDoc("Get returns the value.\n")
+	put := NewFunc("Put", NewTypeSignature(nil, nil, nil), "")
+	intf := NewInterface("Store", pkg, []*Func{get, put})
+	intf.SetDoc("Store stores values.\n\nIt is safe for concurrent use.\n")
+
+	expected := "// Store stores values.\n//\n// It is safe for concurrent use.\n" +
+		"type Store interface{\n// Get returns the value.\nGet();Put()}"
+	if got := intf.PrintCode(pkg.Path(), PackageMap{}); got != expected {
+		t.Errorf("Interface.PrintCode() = %q, want %q", got, expected)
+	}
+
+	s := NewStruct("MockStore", pkg)
+	s.SetDoc("MockStore is a test double for [Store].")
+	m := NewMethod(NewParameter("m", NewPointer(s.Type())), "Get", NewTypeSignature(nil, nil, nil), "")
+	m.SetDoc("Get implements [Store.Get].")
+	s.AddMethod(m)
+
+	code := s.PrintCode(pkg.Path(), PackageMap{})
+	for _, want := range []string{
+		"// MockStore is a test double for [Store].\ntype MockStore struct",
+		"// Get implements [Store.Get].\nfunc (m *MockStore)Get",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Struct.PrintCode() = %q, want to contain %q", code, want)
+		}
+	}
+
+	// no doc
+	if got := NewFunc("F", NewTypeSignature(nil, nil, nil), "").PrintCode(pkg.Path(), PackageMap{}); !strings.HasPrefix(got, "func F") {
+		t.Errorf("Func.PrintCode() = %q, want no doc", got)
+	}
+}
+
+func TestTypeDef(t *testing.T) {
+	pkg := NewPkgInfo("testpkg", "example.com/testpkg", "")
+	inv := NewStruct("Invocation", pkg)
+	handler := NewTypeDef("Handler", pkg, NewTypeSignature(
+		[]*Parameter{NewParameter("inv", NewPointer(inv.Type()))}, nil, nil,
+	))
+	handler.SetDoc("Handler handles the invocation.")
+
+	expected := "// Handler handles the invocation.\ntype Handler func(inv *Invocation)\n"
+	if got := handler.PrintCode(pkg.Path(), PackageMap{}); got != expected {
+		t.Errorf("PrintCode() = %q, want %q", got, expected)
+	}
+
+	typeParams := []*TypeParameter{NewTypeParameter("T", ConstraintAny, 0)}
+	generic := NewGenericTypeDef("List", pkg, NewTypeArray(-1, NewTypeParameter("T", nil, 0)), typeParams)
+	expected = "type List[T any] []T\n"
+	if got := generic.PrintCode(pkg.Path(), PackageMap{}); got != expected {
+		t.Errorf("PrintCode() = %q, want %q", got, expected)
+	}
+}
+
+func TestVar(t *testing.T) {
+	pkg := NewPkgInfo("testpkg", "example.com/testpkg", "")
+	errorType := NewTypeNamed(nil, "error", NewTypeInterface(nil, nil))
+
+	v := NewVar("ErrNotFound", nil, `errors.New("not found")`)
+	v.SetDoc("ErrNotFound is returned if the key is not found.")
+	expected := "// ErrNotFound is returned if the key is not found.\nvar ErrNotFound = errors.New(\"not found\")\n"
+	if got := v.PrintCode(pkg.Path(), PackageMap{}); got != expected {
+		t.Errorf("PrintCode() = %q, want %q", got, expected)
+	}
+
+	expected = "var errLast error = nil\n"
+	if got := NewVar("errLast", errorType, "nil").PrintCode(pkg.Path(), PackageMap{}); got != expected {
+		t.Errorf("PrintCode() = %q, want %q", got, expected)
+	}
+}
+
+func TestTypeDefMethods(t *testing.T) {
+	pkg := NewPkgInfo("testpkg", "example.com/testpkg", "")
+	sig := NewTypeSignature([]*Parameter{NewParameter("a0", NewTypeBasic("string"))}, nil, nil)
+	fn := NewTypeDef("HandlerFunc", pkg, sig)
+	fn.AddMethod(NewMethod(NewParameter("f", fn.Type()), "Handle", sig, "f(a0)"))
+
+	expected := "type HandlerFunc func(a0 string)\n\nfunc (f HandlerFunc)Handle(a0 string){\nf(a0)\n}\n"
+	if got := fn.PrintCode(pkg.Path(), PackageMap{}); got != expected {
+		t.Errorf("PrintCode() = %q, want %q", got, expected)
+	}
+}
@@ -7,6 +7,7 @@ type File struct {
 	path         string
 	pkg          *PkgInfo
 	dependencies *PackageMap
+	imports      []*PkgInfo // packages required by statements
 	contents     []Contents
 }
 
@@ -37,6 +38,12 @@ func (f *File) Dependencies() *PackageMap {
 // DependenciesTidy add missing and remove unused package.
 func (f *File) DependenciesTidy() *PackageMap {
 	f.dependencies.CleanDependencies()
+	for _, pkg := range f.imports {
+		if f.dependencies.Get(pkg.Path()) == nil {
+			f.dependencies.Add(pkg.Path(), *pkg)
+		}
+		f.dependencies.SetRequired(pkg.Path(), true)
+	}
 	for _, c := range f.contents {
 		c.addImports(f.dependencies)
 	}
@@ -74,3 +81,30 @@ func (f *File) AddInterface(intf *Interface) {
 func (f *File) AddStruct(s *Struct) {
 	f.contents = append(f.contents, s)
 }
+
+// AddTypeDef add type definition to file.
+func (f *File) AddTypeDef(d *TypeDef) {
+	f.contents = append(f.contents, d)
+}
+
+// AddFunc add func to file.
+func (f *File) AddFunc(fn *Func) {
+	f.contents = append(f.contents, fn)
+}
+
+// AddVar add variable to file.
+// Packages referred only from its value must be added by AddImport.
+func (f *File) AddVar(v *Var) {
+	f.contents = append(f.contents, v)
+}
+
+// AddAssertion add assertion to file.
+func (f *File) AddAssertion(a *Assertion) {
+	f.contents = append(f.contents, a)
+}
+
+// AddImport add package to file.
+// Use it for packages which are referred only from statements.
+func (f *File) AddImport(pkg *PkgInfo) {
+	f.imports = append(f.imports, pkg)
+}
@@ -26,8 +26,14 @@ func typeImports(typ Type, pm *PackageMap) {
 
 	case *TypeNamed:
 		if t.Pkg() != nil {
+			if pm.Get(t.pkg.Path()) == nil {
+				pm.Add(t.pkg.Path(), *t.pkg)
+			}
 			pm.SetRequired(t.pkg.Path(), true)
 		}
+		for _, arg := range t.TypeArgs() {
+			arg.addImports(pm)
+		}
 
 	case *TypePointer:
 		t.Type().addImports(pm)
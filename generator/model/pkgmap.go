@@ -168,6 +168,15 @@ func (pm *PackageMap) requireImport(myPkgPath string) []string {
 	return paths
 }
 
+// Imports returns the packages myPkgPath need to import.
+func (pm *PackageMap) Imports(myPkgPath string) []*PkgInfo {
+	pkgs := []*PkgInfo{}
+	for _, path := range pm.requireImport(myPkgPath) {
+		pkgs = append(pkgs, pm.Get(path))
+	}
+	return pkgs
+}
+
 // ResolveNameConflict set alias to packages which need to imported  if name is duplicated.
 func (pm *PackageMap) ResolveNameConflict(myPkgPath string) {
 	contains := func(s []string, str string) bool {
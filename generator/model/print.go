@@ -38,28 +38,18 @@ func printType(typ Type, myPkgPath string, pm PackageMap) string {
 		}
 
 	case *TypeInterface:
+		// type parameters of generic interface are printed in the definition of the named type.
 		s := "interface{"
 
-		// Add type parameters if this is a generic interface
-		if len(t.typeParams) > 0 {
-			s = "interface["
-			for i, param := range t.typeParams {
-				if i > 0 {
-					s += ", "
-				}
-				s += param.name
-				if param.constraint != nil {
-					s += " " + param.constraint.PrintType(myPkgPath, pm)
-				}
-			}
-			s += "]{"
-		}
-
 		for _, e := range t.Embeddeds() {
 			s += e.PrintType(myPkgPath, pm)
 			s += ";"
 		}
 		for _, e := range t.ExplicitMethods() {
+			if e.doc != "" {
+				// the doc comment must start on its own line.
+				s += "\n" + printDoc(e.doc)
+			}
 			s += e.PrintDef(myPkgPath, pm)
 			s += ";"
 		}
@@ -97,6 +87,15 @@ func printType(typ Type, myPkgPath string, pm PackageMap) string {
 			result += "]"
 		}
 
+		// Add type arguments if this is an instantiated type
+		if len(t.typeArgs) > 0 {
+			args := []string{}
+			for _, arg := range t.typeArgs {
+				args = append(args, arg.PrintType(myPkgPath, pm))
+			}
+			result += "[" + strings.Join(args, ", ") + "]"
+		}
+
 		return result
 
 	case *TypePointer:
@@ -133,7 +132,10 @@ func (t *TypeSignature) printArgs(myPkgPath string, pm PackageMap) string {
 	}
 	s = strings.TrimRight(s, ",")
 	if t.variadic != nil {
-		s += fmt.Sprintf(",%s ...%s", t.variadic.Name(), t.variadic.Type().PrintType(myPkgPath, pm))
+		if len(t.args) > 0 {
+			s += ","
+		}
+		s += fmt.Sprintf("%s ...%s", t.variadic.Name(), t.variadic.Type().PrintType(myPkgPath, pm))
 	}
 	s += ")"
 	return s
@@ -142,8 +144,10 @@ func (t *TypeSignature) printArgs(myPkgPath string, pm PackageMap) string {
 // printResults print params
 // for example : (x int, y int)
 func (t *TypeSignature) printResults(myPkgPath string, pm PackageMap) string {
+	// a named result needs parentheses even if it is only one.
+	paren := len(t.results) > 1 || (len(t.results) == 1 && t.results[0].Name() != "")
 	s := ""
-	if len(t.results) > 1 {
+	if paren {
 		s += "("
 	}
 	for i, result := range t.results {
@@ -152,8 +156,25 @@ func (t *TypeSignature) printResults(myPkgPath string, pm PackageMap) string {
 		}
 		s += result.PrintNameAndType(myPkgPath, pm)
 	}
-	if len(t.results) > 1 {
+	if paren {
 		s += ")"
 	}
 	return s
 }
+
+// printDoc returns the doc comment lines of doc.
+// for example : // Foo does something.
+func printDoc(doc string) string {
+	if doc == "" {
+		return ""
+	}
+	s := ""
+	for _, line := range strings.Split(strings.TrimRight(doc, "\n"), "\n") {
+		if line == "" {
+			s += "//\n"
+		} else {
+			s += "// " + line + "\n"
+		}
+	}
+	return s
+}
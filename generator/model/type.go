@@ -17,6 +17,7 @@ type TypeParameter struct {
 	name       string
 	constraint Type
 	index      int
+	comparable bool // all the types satisfying the constraint are comparable
 }
 
 // NewTypeParameter returns TypeParameter
@@ -43,6 +44,27 @@ func (tp *TypeParameter) Index() int {
 	return tp.index
 }
 
+// SetComparable sets whether all the types satisfying the constraint are comparable.
+func (tp *TypeParameter) SetComparable(comparable bool) {
+	tp.comparable = comparable
+}
+
+// IsComparable returns true if all the types satisfying the constraint are comparable,
+// such as the type parameter constrained by comparable.
+func (tp *TypeParameter) IsComparable() bool {
+	if tp.comparable {
+		return true
+	}
+	switch c := tp.constraint.(type) {
+	case *TypeConstraint:
+		return c.Name() == ConstraintComparable.Name()
+	case *TypeNamed:
+		// the predeclared comparable
+		return c.Pkg() == nil && c.Name() == ConstraintComparable.Name()
+	}
+	return false
+}
+
 // PrintType returns type parameter representation
 func (tp *TypeParameter) PrintType(myPkgPath string, pm PackageMap) string {
 	return tp.name
@@ -259,6 +281,7 @@ type TypeNamed struct {
 	name       string
 	org        Type
 	typeParams []*TypeParameter
+	typeArgs   []Type
 }
 
 // NewTypeNamed returns TypeNamed.
@@ -281,6 +304,17 @@ func NewGenericTypeNamed(pkg *PkgInfo, name string, org Type, typeParams []*Type
 	}
 }
 
+// NewInstantiatedTypeNamed returns TypeNamed instantiated with type arguments.
+// e.g. Pair[string, int]
+func NewInstantiatedTypeNamed(pkg *PkgInfo, name string, org Type, typeArgs []Type) *TypeNamed {
+	return &TypeNamed{
+		pkg:      pkg,
+		name:     name,
+		org:      org,
+		typeArgs: typeArgs,
+	}
+}
+
 // Pkg returns package info.
 func (t *TypeNamed) Pkg() *PkgInfo {
 	return t.pkg
@@ -306,6 +340,11 @@ func (t *TypeNamed) IsGeneric() bool {
 	return len(t.typeParams) > 0
 }
 
+// TypeArgs returns type arguments if this type is instantiated.
+func (t *TypeNamed) TypeArgs() []Type {
+	return t.typeArgs
+}
+
 // PrintTypeDef returns type definition.
 func (t *TypeNamed) PrintTypeDef(myPkgPath string, pm PackageMap) string {
 	/*
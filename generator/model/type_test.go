@@ -59,6 +59,31 @@ func TestTypeParameter(t *testing.T) {
 	}
 }
 
+func TestTypeParameterIsComparable(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint Type
+		comparable bool
+		expected   bool
+	}{
+		{"comparable constraint", ConstraintComparable, false, true},
+		{"predeclared comparable", NewTypeNamed(nil, "comparable", NewTypeInterface(nil, nil)), false, true},
+		{"named comparable of other package", NewTypeNamed(NewPkgInfo("pkg", "example.com/pkg", ""), "comparable", NewTypeInterface(nil, nil)), false, false},
+		{"any constraint", ConstraintAny, false, false},
+		{"set comparable", NewTypeConstraint("~string | ~int"), true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param := NewTypeParameter("K", tt.constraint, 0)
+			param.SetComparable(tt.comparable)
+			if got := param.IsComparable(); got != tt.expected {
+				t.Errorf("IsComparable() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestTypeConstraint(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -132,6 +157,73 @@ func TestGenericTypeNamed(t *testing.T) {
 	}
 }
 
+func TestInstantiatedTypeNamed(t *testing.T) {
+	pkg := NewPkgInfo("testpkg", "example.com/testpkg", "")
+	other := NewPkgInfo("other", "example.com/other", "")
+	pm := NewPackageMap("testpkg", "example.com/testpkg")
+
+	inst := NewInstantiatedTypeNamed(pkg, "Pair", NewTypeStruct([]*Field{}), []Type{
+		NewTypeBasic("string"),
+		NewTypeArray(-1, NewTypeNamed(other, "Item", NewTypeBasic("int"))),
+	})
+
+	if inst.IsGeneric() {
+		t.Error("IsGeneric() should return false for instantiated type")
+	}
+
+	inst.addImports(pm)
+	if pm.Get("example.com/other") == nil {
+		t.Error("addImports() should add the packages of type arguments")
+	}
+
+	got := inst.PrintType("example.com/mine", *pm)
+	expected := "testpkg.Pair[string, []other.Item]"
+	if got != expected {
+		t.Errorf("PrintType() = %v, want %v", got, expected)
+	}
+}
+
+func TestSignaturePrintType(t *testing.T) {
+	tests := []struct {
+		name     string
+		sig      *TypeSignature
+		expected string
+	}{
+		{
+			name:     "variadic only",
+			sig:      NewTypeSignature(nil, NewParameter("ps", NewTypeArray(-1, NewTypeBasic("byte"))), nil),
+			expected: "func(ps ...[]byte)",
+		},
+		{
+			name: "variadic with args",
+			sig: NewTypeSignature(
+				[]*Parameter{NewParameter("format", NewTypeBasic("string"))},
+				NewParameter("args", NewTypeBasic("any")),
+				nil,
+			),
+			expected: "func(format string,args ...any)",
+		},
+		{
+			name:     "single named result",
+			sig:      NewTypeSignature(nil, nil, []*Parameter{NewParameter("err", NewTypeBasic("error"))}),
+			expected: "func()(err error)",
+		},
+		{
+			name:     "single unnamed result",
+			sig:      NewTypeSignature(nil, nil, []*Parameter{NewParameter("", NewTypeBasic("error"))}),
+			expected: "func() error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sig.PrintType("", PackageMap{}); got != tt.expected {
+				t.Errorf("PrintType() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestGenericTypeInterface(t *testing.T) {
 	typeParams := []*TypeParameter{
 		NewTypeParameter("T", ConstraintAny, 0),
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"fmt"
+	"go/token"
+	"strconv"
+)
+
+// UnexportedRef is a reference to an unexported named type of the other package.
+type UnexportedRef struct {
+	Method string     // name of the method referring to the type
+	Path   string     // path from the method to the type. e.g. "k", "result0[value]", "opts[].Key"
+	Type   *TypeNamed // unexported type
+}
+
+// String returns description of the reference.
+func (r *UnexportedRef) String() string {
+	return fmt.Sprintf("%s: %s: unexported type %s.%s", r.Method, r.Path, r.Type.Pkg().Path(), r.Type.Name())
+}
+
+// UnexportedRefs returns the references to the unexported named types in methods,
+// which cannot be referred from the package of myPkgPath.
+func UnexportedRefs(methods []*Func, myPkgPath string) []*UnexportedRef {
+	refs := []*UnexportedRef{}
+	for _, m := range methods {
+		refs = append(refs, sigUnexportedRefs(m.Name(), "", m.Type(), myPkgPath)...)
+	}
+	return refs
+}
+
+func sigUnexportedRefs(method, prefix string, sig *TypeSignature, myPkgPath string) []*UnexportedRef {
+	refs := []*UnexportedRef{}
+	paramPath := func(p *Parameter, kind string, i int) string {
+		name := p.Name()
+		if name == "" || name == "_" {
+			name = kind + strconv.Itoa(i)
+		}
+		if prefix != "" {
+			return prefix + "." + name
+		}
+		return name
+	}
+
+	for i, p := range sig.Args() {
+		refs = append(refs, typeUnexportedRefs(method, paramPath(p, "arg", i), p.Type(), myPkgPath)...)
+	}
+	if v := sig.Variadic(); v != nil {
+		refs = append(refs, typeUnexportedRefs(method, paramPath(v, "arg", len(sig.Args()))+"...", v.Type(), myPkgPath)...)
+	}
+	for i, p := range sig.Results() {
+		refs = append(refs, typeUnexportedRefs(method, paramPath(p, "result", i), p.Type(), myPkgPath)...)
+	}
+	return refs
+}
+
+func typeUnexportedRefs(method, path string, typ Type, myPkgPath string) []*UnexportedRef {
+	switch t := typ.(type) {
+	case *TypeArray:
+		return typeUnexportedRefs(method, path+"[]", t.Type(), myPkgPath)
+	case *TypeChan:
+		return typeUnexportedRefs(method, path+"<-", t.Type(), myPkgPath)
+	case *TypeMap:
+		refs := typeUnexportedRefs(method, path+"[key]", t.Key(), myPkgPath)
+		return append(refs, typeUnexportedRefs(method, path+"[value]", t.Value(), myPkgPath)...)
+	case *TypePointer:
+		return typeUnexportedRefs(method, path, t.Type(), myPkgPath)
+	case *TypeSignature:
+		return sigUnexportedRefs(method, path, t, myPkgPath)
+	case *TypeStruct:
+		refs := []*UnexportedRef{}
+		for _, f := range t.Fields() {
+			name := f.Name()
+			if name == "" {
+				name = "embedded"
+			}
+			refs = append(refs, typeUnexportedRefs(method, path+"."+name, f.Type(), myPkgPath)...)
+		}
+		return refs
+	case *TypeInterface:
+		refs := []*UnexportedRef{}
+		for _, e := range t.Embeddeds() {
+			refs = append(refs, typeUnexportedRefs(method, path, e, myPkgPath)...)
+		}
+		for _, m := range t.ExplicitMethods() {
+			refs = append(refs, sigUnexportedRefs(method, path+"."+m.Name(), m.Type(), myPkgPath)...)
+		}
+		return refs
+	case *TypeNamed:
+		// only the name and the type arguments of the named type are printed,
+		// so its underlying type is not walked.
+		refs := []*UnexportedRef{}
+		if t.Pkg() != nil && t.Pkg().Path() != myPkgPath && !token.IsExported(t.Name()) {
+			refs = append(refs, &UnexportedRef{Method: method, Path: path, Type: t})
+		}
+		for _, arg := range t.TypeArgs() {
+			refs = append(refs, typeUnexportedRefs(method, path+"[]", arg, myPkgPath)...)
+		}
+		return refs
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import "testing"
+
+func TestUnexportedRefs(t *testing.T) {
+	other := NewPkgInfo("other", "example.com/other", "")
+	hidden := NewTypeNamed(other, "key", NewTypeBasic("string"))
+	public := NewTypeNamed(other, "Key", NewTypeBasic("string"))
+	own := NewTypeNamed(NewPkgInfo("mine", "example.com/mine", ""), "key", NewTypeBasic("string"))
+
+	methods := []*Func{
+		NewFunc("Get", NewTypeSignature(
+			[]*Parameter{NewParameter("k", hidden)},
+			nil,
+			[]*Parameter{NewParameter("", NewTypeMap(NewTypeBasic("string"), NewPointer(hidden)))},
+		), ""),
+		NewFunc("Each", NewTypeSignature(
+			[]*Parameter{NewParameter("fn", NewTypeSignature([]*Parameter{NewParameter("", hidden)}, nil, nil))},
+			NewParameter("opts", NewTypeArray(-1, NewTypeStruct([]*Field{NewField("Key", hidden, "")}))),
+			nil,
+		), ""),
+		NewFunc("Put", NewTypeSignature(
+			[]*Parameter{NewParameter("k", public), NewParameter("o", own)},
+			nil, nil,
+		), ""),
+	}
+
+	refs := UnexportedRefs(methods, "example.com/mine")
+
+	expected := []string{
+		"Get: k: unexported type example.com/other.key",
+		"Get: result0[value]: unexported type example.com/other.key",
+		"Each: fn.arg0: unexported type example.com/other.key",
+		"Each: opts...[].Key: unexported type example.com/other.key",
+	}
+	if len(refs) != len(expected) {
+		t.Fatalf("UnexportedRefs() = %v, want %v", refs, expected)
+	}
+	for i, ref := range refs {
+		if ref.String() != expected[i] {
+			t.Errorf("UnexportedRefs()[%d] = %v, want %v", i, ref, expected[i])
+		}
+	}
+
+	if refs := UnexportedRefs(methods[:2], "example.com/other"); len(refs) != 0 {
+		t.Errorf("UnexportedRefs() in the same package = %v, want none", refs)
+	}
+}
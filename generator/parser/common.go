@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+// Conflict is the method which has the different signatures among the structs.
+type Conflict struct {
+	Method     string
+	Types      []string // structs which have the method
+	Signatures []string // signature of the method of each struct
+}
+
+// String returns description of the conflict.
+func (c *Conflict) String() string {
+	sigs := []string{}
+	for i := range c.Types {
+		sigs = append(sigs, c.Types[i]+": "+c.Signatures[i])
+	}
+	return fmt.Sprintf("%s: %s", c.Method, strings.Join(sigs, ", "))
+}
+
+// ParseCommon parses the struct targets, and returns the package
+// whose interface named name has the methods common to all of them.
+// The methods are common if their signatures are identical ignoring parameter names.
+// The methods with the same name and the different signatures are returned as conflicts.
+func (p *Parser) ParseCommon(name string) (*model.Package, []*Conflict, error) {
+	pkg, err := p.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// methods of each struct
+	methods := []map[string]*types.Selection{}
+	for _, tname := range p.Targets {
+		obj := p.ParsedPkg.Pkg.Scope().Lookup(tname)
+		if !isStruct(obj.Type()) {
+			return nil, nil, fmt.Errorf("%s is not struct", tname)
+		}
+		if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+			return nil, nil, fmt.Errorf("generic struct %s is unsupported", tname)
+		}
+		m := map[string]*types.Selection{}
+		for _, sel := range p.structMethods(obj.Type()) {
+			m[sel.Obj().Name()] = sel
+		}
+		methods = append(methods, m)
+	}
+
+	qualifier := types.RelativeTo(p.ParsedPkg.Pkg)
+	common := []*types.Selection{}
+	conflicts := []*Conflict{}
+	seen := map[string]bool{}
+	for i := range methods {
+		for mname, sel := range methods[i] {
+			if seen[mname] {
+				continue
+			}
+			seen[mname] = true
+
+			conflict := &Conflict{Method: mname}
+			identical, all := true, true
+			for j, m := range methods {
+				other, ok := m[mname]
+				if !ok {
+					all = false
+					continue
+				}
+				conflict.Types = append(conflict.Types, p.Targets[j])
+				conflict.Signatures = append(conflict.Signatures, strings.TrimPrefix(types.TypeString(methodSignature(other), qualifier), "func"))
+				if !types.Identical(methodSignature(sel), methodSignature(other)) {
+					identical = false
+				}
+			}
+			if !identical {
+				conflicts = append(conflicts, conflict)
+			} else if all {
+				common = append(common, sel)
+			}
+		}
+	}
+	if err := p.sortMethods(common); err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Method < conflicts[j].Method
+	})
+
+	tp := p.newTypeParser()
+	funcs := []*model.Func{}
+	for _, sel := range common {
+		typ, err := tp.parseType(methodSignature(sel))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse method %s: %v", sel.Obj().Name(), err)
+		}
+		sig, ok := typ.(*model.TypeSignature)
+		if !ok {
+			return nil, nil, fmt.Errorf("internal error. %s is %T", sel.Obj().Name(), typ)
+		}
+		// the common methods are selected from the first type, which documents them.
+		method := model.NewFunc(sel.Obj().Name(), sig, "")
+		method.SetDoc(p.methodDoc(sel.Obj()))
+		funcs = append(funcs, method)
+	}
+
+	pkgInfo := model.NewPkgInfo(pkg.Name, pkg.Path, "")
+	pkg.Interfaces = []*model.Interface{model.NewInterface(name, pkgInfo, funcs)}
+	return pkg, conflicts, nil
+}
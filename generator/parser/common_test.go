@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCommon(t *testing.T) {
+	src := `package test
+import "context"
+type FileStore struct{}
+func (*FileStore) Get(ctx context.Context, key string) ([]byte, error) { return nil, nil }
+func (*FileStore) Put(key string, v []byte) error { return nil }
+func (*FileStore) Close() error { return nil }
+func (*FileStore) Path() string { return "" }
+type S3Store struct{}
+func (*S3Store) Get(c context.Context, k string) (b []byte, err error) { return }
+func (*S3Store) Put(ctx context.Context, key string, v []byte) error { return nil }
+func (S3Store) Close() error { return nil }
+type MemStore struct{}
+func (m *MemStore) Get(_ context.Context, key string) ([]byte, error) { return nil, nil }
+func (m *MemStore) Put(key string, v []byte) error { return nil }
+func (m *MemStore) Close() error { return nil }
+`
+	p := NewParser(
+		OptPackage(checkTestPackage(t, src)),
+		OptParseTarget([]string{"FileStore", "S3Store", "MemStore"}),
+	)
+	pkg, conflicts, err := p.ParseCommon("Store")
+	if err != nil {
+		t.Fatalf("ParseCommon() error = %v", err)
+	}
+
+	if len(pkg.Interfaces) != 1 || pkg.Interfaces[0].Name() != "Store" {
+		t.Fatalf("ParseCommon() interfaces = %v, want only Store", pkg.Interfaces)
+	}
+	names := []string{}
+	for _, m := range pkg.Interfaces[0].Methods() {
+		names = append(names, m.Name())
+	}
+	if got := strings.Join(names, ","); got != "Close,Get" {
+		t.Errorf("methods = %v, want %v", got, "Close,Get")
+	}
+	if len(pkg.Structs) != 3 {
+		t.Errorf("structs = %d, want %d", len(pkg.Structs), 3)
+	}
+
+	if len(conflicts) != 1 {
+		t.Fatalf("conflicts = %v, want 1 conflict", conflicts)
+	}
+	expected := "Put: FileStore: (key string, v []byte) error, S3Store: (ctx context.Context, key string, v []byte) error, MemStore: (key string, v []byte) error"
+	if conflicts[0].String() != expected {
+		t.Errorf("conflict = %v, want %v", conflicts[0], expected)
+	}
+}
+
+func TestParseCommonDoc(t *testing.T) {
+	src := `package test
+type FileStore struct{}
+// Get reads the file of key.
+func (*FileStore) Get(key string) ([]byte, error) { return nil, nil }
+func (*FileStore) Close() error { return nil }
+type MemStore struct{}
+// Get returns the value of key.
+func (*MemStore) Get(key string) ([]byte, error) { return nil, nil }
+// Close clears the values.
+func (*MemStore) Close() error { return nil }
+`
+	p := NewParser(
+		OptPackage(checkTestPackage(t, src)),
+		OptParseTarget([]string{"FileStore", "MemStore"}),
+	)
+	pkg, _, err := p.ParseCommon("Store")
+	if err != nil {
+		t.Fatalf("ParseCommon() error = %v", err)
+	}
+	// the docs are copied from the first type.
+	checkMethodDocs(t, pkg.Interfaces[0], map[string]string{
+		"Close": "",
+		"Get":   "Get reads the file of key.\n",
+	})
+}
+
+func TestParseCommonGeneric(t *testing.T) {
+	src := `package test
+type A struct{}
+func (A) Get() int { return 0 }
+type B[T any] struct{}
+func (B[T]) Get() int { return 0 }
+`
+	p := NewParser(
+		OptPackage(checkTestPackage(t, src)),
+		OptParseTarget([]string{"A", "B"}),
+	)
+	if _, _, err := p.ParseCommon("Getter"); err == nil {
+		t.Error("ParseCommon() should return error for generic struct")
+	}
+}
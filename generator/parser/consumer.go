@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Consumer is the package which uses the target type.
+type Consumer struct {
+	Name    string
+	Path    string
+	Methods []string // methods of the target type used in the package
+}
+
+// LoadConsumer loads the consumer package matching pattern,
+// and finds the methods of typ used in it.
+// typ is the type referred from the consumer, such as "*stripe.Client", "stripe.Client"
+// or "github.com/stripe/stripe-go/client.Client". Without the package, it is the type of the consumer.
+// The package of typ is set as the package to parse, and the type as the target.
+// In OrderSource, the package of typ is loaded from source to read the positions of the methods.
+func (p *Parser) LoadConsumer(pattern, typ string) (*Consumer, error) {
+	cfg := &packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedTypes | packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedModule,
+		Tests: false,
+	}
+	pkg, err := p.load(cfg, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	qualifier, name := splitTypeRef(typ)
+	target := pkg.Types
+	if qualifier != "" {
+		target, err = importedPackage(pkg.Types, qualifier)
+		if err != nil {
+			return nil, err
+		}
+	}
+	obj, ok := target.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%s not found in %s", name, target.Path())
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%s is not named type", typ)
+	}
+
+	methods := usedMethods(pkg.TypesInfo, named)
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("no methods of %s are used in %s", typ, pkg.PkgPath)
+	}
+
+	p.ParsedPkg = &Package{
+		Name: target.Name(),
+		Pkg:  target,
+	}
+	if target == pkg.Types {
+		p.ParsedPkg.Files = pkg.Syntax
+		p.ParsedPkg.Fset = pkg.Fset
+	} else if p.order == OrderSource {
+		// positions of the declarations are read from the syntax, so the package of typ is loaded from source.
+		if err := p.LoadPackage(target.Path()); err != nil {
+			return nil, err
+		}
+	} else if files, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedFiles}, target.Path()); err == nil && len(files) == 1 {
+		// only the file names to read the doc comments
+		p.ParsedPkg.GoFiles = files[0].GoFiles
+	}
+	p.Targets = []string{name}
+
+	return &Consumer{
+		Name:    pkg.Name,
+		Path:    pkg.PkgPath,
+		Methods: methods,
+	}, nil
+}
+
+// splitTypeRef splits the type reference such as "*pkg.Type" into the package and the name.
+func splitTypeRef(typ string) (qualifier, name string) {
+	typ = strings.TrimPrefix(typ, "*")
+	i := strings.LastIndex(typ, ".")
+	if i < 0 {
+		return "", typ
+	}
+	return typ[:i], typ[i+1:]
+}
+
+// importedPackage returns the package imported by pkg, whose path or name is qualifier.
+func importedPackage(pkg *types.Package, qualifier string) (*types.Package, error) {
+	found := []*types.Package{}
+	for _, imp := range pkg.Imports() {
+		if imp.Path() == qualifier {
+			return imp, nil
+		}
+		if imp.Name() == qualifier {
+			found = append(found, imp)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%s is not imported by %s", qualifier, pkg.Path())
+	case 1:
+		return found[0], nil
+	default:
+		paths := []string{}
+		for _, f := range found {
+			paths = append(paths, f.Path())
+		}
+		return nil, fmt.Errorf("%s is ambiguous: %s", qualifier, strings.Join(paths, ", "))
+	}
+}
+
+// usedMethods returns the names of the methods selected on the values of named, in sorted order.
+func usedMethods(info *types.Info, named *types.Named) []string {
+	used := map[string]bool{}
+	for _, sel := range info.Selections {
+		if sel.Kind() != types.MethodVal && sel.Kind() != types.MethodExpr {
+			continue
+		}
+		recv := sel.Recv()
+		if ptr, ok := recv.(*types.Pointer); ok {
+			recv = ptr.Elem()
+		}
+		n, ok := recv.(*types.Named)
+		if !ok || n.Origin() != named.Origin() {
+			continue
+		}
+		if sel.Obj().Exported() {
+			used[sel.Obj().Name()] = true
+		}
+	}
+
+	methods := make([]string, 0, len(used))
+	for m := range used {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	return methods
+}
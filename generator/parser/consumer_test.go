@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+type testImporter map[string]*types.Package
+
+func (m testImporter) Import(path string) (*types.Package, error) {
+	return m[path], nil
+}
+
+// checkConsumer type-checks the consumer source importing the package of lib.
+func checkConsumer(t *testing.T, lib, src string) (*types.Package, *types.Package, *types.Info) {
+	t.Helper()
+	fset := token.NewFileSet()
+	check := func(path, src string, imp types.Importer, info *types.Info) *types.Package {
+		f, err := goparser.ParseFile(fset, path+".go", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg, err := (&types.Config{Importer: imp}).Check(path, fset, []*ast.File{f}, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pkg
+	}
+	libPkg := check("example.com/stripe", lib, nil, nil)
+	info := &types.Info{Selections: map[*ast.SelectorExpr]*types.Selection{}}
+	pkg := check("example.com/billing", src, testImporter{"example.com/stripe": libPkg}, info)
+	return libPkg, pkg, info
+}
+
+func TestUsedMethods(t *testing.T) {
+	lib := `package stripe
+type Client struct{}
+func (c *Client) Charge(amount int) error { return nil }
+func (c *Client) Refund(id string) error { return nil }
+func (c *Client) Customers() []string { return nil }
+func (c Client) Version() string { return "" }
+type Other struct{}
+func (Other) Charge(amount int) error { return nil }
+`
+	src := `package billing
+import "example.com/stripe"
+type Service struct{ c *stripe.Client; o stripe.Other }
+func (s *Service) Pay() error {
+	if err := s.c.Charge(10); err != nil {
+		return s.c.Refund("x")
+	}
+	_ = s.o.Charge(1)
+	f := (*stripe.Client).Version
+	_ = f
+	return nil
+}
+`
+	libPkg, _, info := checkConsumer(t, lib, src)
+	named := libPkg.Scope().Lookup("Client").Type().(*types.Named)
+
+	got := usedMethods(info, named)
+	expected := []string{"Charge", "Refund", "Version"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("usedMethods() = %v, want %v", got, expected)
+	}
+}
+
+func TestSplitTypeRef(t *testing.T) {
+	tests := []struct {
+		typ       string
+		qualifier string
+		name      string
+	}{
+		{"*stripe.Client", "stripe", "Client"},
+		{"stripe.Client", "stripe", "Client"},
+		{"Client", "", "Client"},
+		{"gopkg.in/yaml.v3.Node", "gopkg.in/yaml.v3", "Node"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			qualifier, name := splitTypeRef(tt.typ)
+			if qualifier != tt.qualifier || name != tt.name {
+				t.Errorf("splitTypeRef(%v) = %v, %v, want %v, %v", tt.typ, qualifier, name, tt.qualifier, tt.name)
+			}
+		})
+	}
+}
+
+func TestImportedPackage(t *testing.T) {
+	_, pkg, _ := checkConsumer(t, "package stripe\n", "package billing\nimport _ \"example.com/stripe\"\n")
+
+	for _, q := range []string{"stripe", "example.com/stripe"} {
+		got, err := importedPackage(pkg, q)
+		if err != nil {
+			t.Fatalf("importedPackage(%v) error = %v", q, err)
+		}
+		if got.Path() != "example.com/stripe" {
+			t.Errorf("importedPackage(%v) = %v, want %v", q, got.Path(), "example.com/stripe")
+		}
+	}
+	if _, err := importedPackage(pkg, "paypal"); err == nil {
+		t.Error("importedPackage() should return error for the package not imported")
+	}
+}
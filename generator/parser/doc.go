@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+)
+
+// docs is the doc comments of the declarations in the parsed package.
+type docs struct {
+	types   map[string]string // type name -> doc
+	methods map[string]string // "Type.Method" -> doc
+	funcs   map[string]string // package-level function name -> doc
+}
+
+func newDocs() *docs {
+	return &docs{
+		types:   map[string]string{},
+		methods: map[string]string{},
+		funcs:   map[string]string{},
+	}
+}
+
+// loadDocs returns the doc comments of the parsed package.
+// The syntax is used if it is loaded, otherwise the files are parsed only for the comments.
+func (p *Parser) loadDocs() *docs {
+	if p.docs != nil {
+		return p.docs
+	}
+	if len(p.ParsedPkg.Files) == 0 {
+		p.docs = p.parseDocs(p.ParsedPkg.GoFiles)
+		return p.docs
+	}
+	p.docs = newDocs()
+	for _, f := range p.ParsedPkg.Files {
+		p.docs.add(f)
+	}
+	return p.docs
+}
+
+// parseDocs returns the doc comments of the files, which are parsed only for the comments.
+func (p *Parser) parseDocs(files []string) *docs {
+	d := newDocs()
+	fset := token.NewFileSet()
+	for _, name := range files {
+		f, err := goparser.ParseFile(fset, name, nil, goparser.ParseComments|goparser.SkipObjectResolution)
+		if err != nil {
+			p.log.Printf("[WARN] doc comments are not loaded: %s\n", err)
+			continue
+		}
+		d.add(f)
+	}
+	return d
+}
+
+// add adds the doc comments of the type, method and function declarations in f.
+func (d *docs) add(f *ast.File) {
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				ts := spec.(*ast.TypeSpec)
+				doc := ts.Doc
+				if doc == nil && !decl.Lparen.IsValid() {
+					// type Foo struct{}
+					doc = decl.Doc
+				}
+				d.types[ts.Name.Name] = doc.Text()
+
+				intf, ok := ts.Type.(*ast.InterfaceType)
+				if !ok {
+					continue
+				}
+				for _, field := range intf.Methods.List {
+					for _, name := range field.Names {
+						d.methods[ts.Name.Name+"."+name.Name] = field.Doc.Text()
+					}
+				}
+			}
+
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) == 0 {
+				d.funcs[decl.Name.Name] = decl.Doc.Text()
+				continue
+			}
+			if recv := recvTypeName(decl.Recv.List[0].Type); recv != "" {
+				d.methods[recv+"."+decl.Name.Name] = decl.Doc.Text()
+			}
+		}
+	}
+}
+
+// recvTypeName returns the type name of the receiver such as *Foo, Foo[T] and *Foo[K, V].
+func recvTypeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return recvTypeName(e.X)
+	case *ast.ParenExpr:
+		return recvTypeName(e.X)
+	case *ast.IndexExpr:
+		return recvTypeName(e.X)
+	case *ast.IndexListExpr:
+		return recvTypeName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// typeDoc returns the doc comment of the type declared in the parsed package.
+func (p *Parser) typeDoc(obj types.Object) string {
+	if p.ParsedPkg == nil || obj.Pkg() != p.ParsedPkg.Pkg {
+		return ""
+	}
+	return p.loadDocs().types[obj.Name()]
+}
+
+// methodDoc returns the doc comment of the method declared in the parsed package.
+// The method of an interface is documented in the interface declaring it.
+func (p *Parser) methodDoc(obj types.Object) string {
+	fn, ok := obj.(*types.Func)
+	if !ok || p.ParsedPkg == nil || fn.Pkg() != p.ParsedPkg.Pkg {
+		return ""
+	}
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return ""
+	}
+	t := sig.Recv().Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return ""
+	}
+	return p.loadDocs().methods[named.Origin().Obj().Name()+"."+fn.Name()]
+}
+
+// funcDoc returns the doc comment of the package-level function loaded by LoadFuncs.
+func (p *Parser) funcDoc(fn *types.Func) string {
+	d, ok := p.funcDocs[fn.Pkg().Path()]
+	if !ok {
+		return ""
+	}
+	return d.funcs[fn.Name()]
+}
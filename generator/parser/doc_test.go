@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+const docTestSrc = `package test
+
+// Base is embedded.
+type Base struct{}
+
+// Close releases the resources.
+func (*Base) Close() error { return nil }
+
+// UserService manages users.
+//
+// It is safe for concurrent use.
+type UserService struct {
+	Base
+}
+
+// GetUser returns the user.
+func (s *UserService) GetUser(id string) string { return id }
+
+func (s *UserService) Ping() {}
+
+type (
+	// Cache caches values.
+	Cache[K comparable, V any] struct{}
+
+	// Reader reads.
+	Reader interface {
+		// Read reads p.
+		Read(p []byte) (int, error)
+	}
+)
+
+// Get returns the cached value.
+func (c *Cache[K, V]) Get(k K) (V, bool) { var v V; return v, false }
+
+// ReadCloser reads and closes.
+type ReadCloser interface {
+	Reader
+	// Close closes.
+	Close() error
+}
+`
+
+func TestDoc(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		expectedDoc string
+		methodDocs  map[string]string
+	}{
+		{
+			name:        "struct",
+			target:      "UserService",
+			expectedDoc: "UserService manages users.\n\nIt is safe for concurrent use.\n",
+			methodDocs: map[string]string{
+				"Close":   "Close releases the resources.\n",
+				"GetUser": "GetUser returns the user.\n",
+				"Ping":    "",
+			},
+		},
+		{
+			name:        "generic struct in grouped declaration",
+			target:      "Cache",
+			expectedDoc: "Cache caches values.\n",
+			methodDocs: map[string]string{
+				"Get": "Get returns the cached value.\n",
+			},
+		},
+		{
+			name:        "interface",
+			target:      "ReadCloser",
+			expectedDoc: "ReadCloser reads and closes.\n",
+			methodDocs: map[string]string{
+				"Close": "Close closes.\n",
+				"Read":  "Read reads p.\n",
+			},
+		},
+	}
+
+	check := func(t *testing.T, pkg *Package) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				p := NewParser(OptPackage(pkg), OptParseTarget([]string{tt.target}))
+				got, err := p.Parse()
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+				intf := got.Interfaces[0]
+				if len(got.Structs) > 0 {
+					// the doc of the struct is not carried onto the interface of its methods.
+					if got.Structs[0].Doc() != tt.expectedDoc {
+						t.Errorf("Struct.Doc() = %q, want %q", got.Structs[0].Doc(), tt.expectedDoc)
+					}
+					if intf.Doc() != "" {
+						t.Errorf("Interface.Doc() = %q, want empty", intf.Doc())
+					}
+				} else if intf.Doc() != tt.expectedDoc {
+					t.Errorf("Interface.Doc() = %q, want %q", intf.Doc(), tt.expectedDoc)
+				}
+				checkMethodDocs(t, intf, tt.methodDocs)
+			})
+		}
+	}
+
+	t.Run("syntax", func(t *testing.T) {
+		check(t, checkTestPackage(t, docTestSrc))
+	})
+
+	t.Run("files", func(t *testing.T) {
+		// the syntax is not loaded, so the files are parsed for the comments.
+		pkg := checkTestPackage(t, docTestSrc)
+		file := filepath.Join(t.TempDir(), "test.go")
+		if err := os.WriteFile(file, []byte(docTestSrc), 0644); err != nil {
+			t.Fatal(err)
+		}
+		pkg.Files, pkg.Fset, pkg.GoFiles = nil, nil, []string{file}
+		check(t, pkg)
+	})
+}
+
+func checkMethodDocs(t *testing.T, intf *model.Interface, expected map[string]string) {
+	t.Helper()
+	if len(intf.Methods()) != len(expected) {
+		t.Fatalf("%s has %d methods, want %d", intf.Name(), len(intf.Methods()), len(expected))
+	}
+	for _, m := range intf.Methods() {
+		want, ok := expected[m.Name()]
+		if !ok {
+			t.Errorf("unexpected method %s", m.Name())
+			continue
+		}
+		if m.Doc() != want {
+			t.Errorf("%s.Doc() = %q, want %q", m.Name(), m.Doc(), want)
+		}
+	}
+}
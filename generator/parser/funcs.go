@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/kmio11/codegen/generator/model"
+	"golang.org/x/tools/go/packages"
+)
+
+// SplitFuncRef splits the reference to the package-level function such as "os.ReadFile"
+// or "path/filepath.Glob" into the import path and the name.
+func SplitFuncRef(ref string) (path, name string, err error) {
+	i := strings.LastIndex(ref, ".")
+	if i <= 0 || i == len(ref)-1 {
+		return "", "", fmt.Errorf("invalid function %q: must be <import path>.<name>", ref)
+	}
+	return ref[:i], ref[i+1:], nil
+}
+
+// LoadFuncs loads the package matching pattern as the package to parse,
+// and the package-level functions referred by refs such as "os.ReadFile".
+// Only the name of the package matching pattern is loaded, since it is the output package.
+// The files of the packages of the functions are parsed for their doc comments.
+func (p *Parser) LoadFuncs(pattern string, refs []string) ([]*types.Func, error) {
+	home, err := p.load(&packages.Config{Mode: packages.NeedName | packages.NeedFiles}, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(home.Errors) > 0 {
+		return nil, fmt.Errorf("failed to load %s: %v", pattern, home.Errors[0])
+	}
+	if home.Name == "" {
+		return nil, fmt.Errorf("no Go package found in %s", pattern)
+	}
+
+	paths := []string{}
+	names := []string{}
+	for _, ref := range refs {
+		path, name, err := SplitFuncRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+		names = append(names, name)
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedTypes | packages.NeedTypesSizes,
+	}
+	pkgs, err := packages.Load(cfg, paths...)
+	if err != nil {
+		p.log.Println(err)
+		return nil, err
+	}
+	if err := p.PrintErrors(pkgs); err != nil {
+		p.log.Println(err)
+		return nil, err
+	}
+	loaded := map[string]*types.Package{}
+	p.funcDocs = map[string]*docs{}
+	for _, pkg := range pkgs {
+		loaded[pkg.PkgPath] = pkg.Types
+		p.funcDocs[pkg.PkgPath] = p.parseDocs(pkg.GoFiles)
+	}
+
+	funcs := []*types.Func{}
+	for i, path := range paths {
+		pkg, ok := loaded[path]
+		if !ok || pkg == nil {
+			return nil, fmt.Errorf("package %s not found", path)
+		}
+		fn, ok := pkg.Scope().Lookup(names[i]).(*types.Func)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a function", refs[i])
+		}
+		funcs = append(funcs, fn)
+	}
+
+	p.ParsedPkg = &Package{
+		Name:    home.Name,
+		Pkg:     types.NewPackage(home.PkgPath, home.Name),
+		GoFiles: home.GoFiles,
+	}
+	return funcs, nil
+}
+
+// ParseFuncs returns the package whose interface named name has the methods
+// mirroring the package-level functions, which are set as the Functions of the package.
+// The methods are sorted by name, or in the order of funcs if the order is OrderSource.
+func (p *Parser) ParseFuncs(name string, funcs []*types.Func) (*model.Package, error) {
+	if p.ParsedPkg == nil {
+		err := fmt.Errorf("invalid parser settings")
+		p.log.Println(err)
+		return nil, err
+	}
+	pkg, err := p.getPackageBase()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]*types.Func{}
+	methods := []*model.Func{}
+	for _, fn := range funcs {
+		sig, ok := fn.Type().(*types.Signature)
+		if !ok || sig.Recv() != nil {
+			return nil, fmt.Errorf("%s.%s is not a package-level function", fn.Pkg().Path(), fn.Name())
+		}
+		if sig.TypeParams().Len() > 0 {
+			return nil, fmt.Errorf("generic function %s.%s cannot be a method", fn.Pkg().Path(), fn.Name())
+		}
+		if dup, ok := seen[fn.Name()]; ok {
+			return nil, fmt.Errorf("duplicate method %s: %s.%s and %s.%s", fn.Name(), dup.Pkg().Path(), dup.Name(), fn.Pkg().Path(), fn.Name())
+		}
+		seen[fn.Name()] = fn
+
+		typ, err := p.parseType(sig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse function %s: %v", fn.Name(), err)
+		}
+		msig, ok := typ.(*model.TypeSignature)
+		if !ok {
+			return nil, fmt.Errorf("internal error. %s is %T", fn.Name(), typ)
+		}
+
+		fnPkg := model.NewPkgInfo(fn.Pkg().Name(), fn.Pkg().Path(), "")
+		pkg.Functions = append(pkg.Functions, model.NewPackageFunc(fnPkg, fn.Name(), msig))
+		method := model.NewFunc(fn.Name(), msig, "")
+		method.SetDoc(p.funcDoc(fn))
+		methods = append(methods, method)
+	}
+	if p.order == OrderName {
+		sort.SliceStable(methods, func(i, j int) bool {
+			return methods[i].Name() < methods[j].Name()
+		})
+	}
+
+	pkgInfo := model.NewPkgInfo(pkg.Name, pkg.Path, "")
+	pkg.Interfaces = []*model.Interface{model.NewInterface(name, pkgInfo, methods)}
+	return pkg, nil
+}
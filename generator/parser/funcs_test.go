@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitFuncRef(t *testing.T) {
+	tests := []struct {
+		ref          string
+		expectedPath string
+		expectedName string
+		expectErr    bool
+	}{
+		{ref: "os.ReadFile", expectedPath: "os", expectedName: "ReadFile"},
+		{ref: "path/filepath.Glob", expectedPath: "path/filepath", expectedName: "Glob"},
+		{ref: "gopkg.in/yaml.v3.Marshal", expectedPath: "gopkg.in/yaml.v3", expectedName: "Marshal"},
+		{ref: "ReadFile", expectErr: true},
+		{ref: "os.", expectErr: true},
+		{ref: ".ReadFile", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			path, name, err := SplitFuncRef(tt.ref)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("SplitFuncRef() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if path != tt.expectedPath || name != tt.expectedName {
+				t.Errorf("SplitFuncRef() = %v, %v, want %v, %v", path, name, tt.expectedPath, tt.expectedName)
+			}
+		})
+	}
+}
+
+func TestParseFuncs(t *testing.T) {
+	src := checkTestPackage(t, `package test
+
+import "io/fs"
+
+func ReadFile(name string) ([]byte, error) { return nil, nil }
+func Stat(name string) (fs.FileInfo, error) { return nil, nil }
+func Printf(format string, a ...any) {}
+func Map[T any](v T) T { return v }
+
+type Client struct{}
+
+func (Client) Get() {}
+`).Pkg
+	lookup := func(names ...string) []*types.Func {
+		funcs := []*types.Func{}
+		for _, name := range names {
+			if fn, ok := src.Scope().Lookup(name).(*types.Func); ok {
+				funcs = append(funcs, fn)
+			}
+		}
+		return funcs
+	}
+	home := &Package{Name: "sys", Pkg: types.NewPackage("example.com/sys", "sys")}
+
+	tests := []struct {
+		name      string
+		funcs     []*types.Func
+		order     MethodOrder
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "sorted by name",
+			funcs:    lookup("Stat", "ReadFile", "Printf"),
+			order:    OrderName,
+			expected: "Printf(format string,a ...interface{});ReadFile(name string)( []byte, error);Stat(name string)( fs.FileInfo, error)",
+		},
+		{
+			name:     "listed order",
+			funcs:    lookup("Stat", "ReadFile"),
+			order:    OrderSource,
+			expected: "Stat(name string)( fs.FileInfo, error);ReadFile(name string)( []byte, error)",
+		},
+		{
+			name:      "generic function",
+			funcs:     lookup("Map"),
+			expectErr: true,
+		},
+		{
+			name:      "duplicate name",
+			funcs:     lookup("Stat", "Stat"),
+			expectErr: true,
+		},
+		{
+			name:      "method",
+			funcs:     []*types.Func{src.Scope().Lookup("Client").Type().(*types.Named).Method(0)},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(OptPackage(home), OptMethodOrder(tt.order))
+			pkg, err := p.ParseFuncs("Sys", tt.funcs)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ParseFuncs() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err != nil {
+				return
+			}
+			if pkg.Path != "example.com/sys" || len(pkg.Interfaces) != 1 || pkg.Interfaces[0].Name() != "Sys" {
+				t.Fatalf("ParseFuncs() = %s %v, want interface Sys in example.com/sys", pkg.Path, pkg.Interfaces)
+			}
+			defs := []string{}
+			for _, m := range pkg.Interfaces[0].Methods() {
+				defs = append(defs, m.PrintDef(pkg.Path, *pkg.Dependencies))
+			}
+			if got := strings.Join(defs, ";"); got != tt.expected {
+				t.Errorf("ParseFuncs() methods = %v, want %v", got, tt.expected)
+			}
+			if len(pkg.Functions) != len(tt.funcs) {
+				t.Fatalf("ParseFuncs() functions = %d, want %d", len(pkg.Functions), len(tt.funcs))
+			}
+			for _, fn := range pkg.Functions {
+				if got := fn.PrintRef(pkg.Path, *pkg.Dependencies); got != "test."+fn.Name() {
+					t.Errorf("PrintRef() = %v, want test.%v", got, fn.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestParseFuncsDoc(t *testing.T) {
+	const src = `package test
+
+// ReadFile reads the file.
+func ReadFile(name string) ([]byte, error) { return nil, nil }
+
+func Stat(name string) error { return nil }
+`
+	pkg := checkTestPackage(t, src).Pkg
+	file := filepath.Join(t.TempDir(), "test.go")
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	home := &Package{Name: "sys", Pkg: types.NewPackage("example.com/sys", "sys")}
+	p := NewParser(OptPackage(home))
+	p.funcDocs = map[string]*docs{pkg.Path(): p.parseDocs([]string{file})}
+
+	got, err := p.ParseFuncs("Sys", []*types.Func{
+		pkg.Scope().Lookup("ReadFile").(*types.Func),
+		pkg.Scope().Lookup("Stat").(*types.Func),
+	})
+	if err != nil {
+		t.Fatalf("ParseFuncs() error = %v", err)
+	}
+	checkMethodDocs(t, got.Interfaces[0], map[string]string{
+		"ReadFile": "ReadFile reads the file.\n",
+		"Stat":     "",
+	})
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
 
 	"golang.org/x/tools/go/packages"
@@ -10,9 +11,11 @@ import (
 
 // A Package contains all the information related to a parsed package.
 type Package struct {
-	Name  string
-	Files []*ast.File
-	Pkg   *types.Package
+	Name    string
+	Files   []*ast.File
+	Fset    *token.FileSet // positions of Files; nil if the syntax is not loaded
+	GoFiles []string       // source files, parsed for the doc comments if the syntax is not loaded
+	Pkg     *types.Package
 }
 
 // LoadPackage parse package.
@@ -21,30 +24,54 @@ func (p *Parser) LoadPackage(patterns ...string) error {
 		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedTypes | packages.NeedTypesSizes | packages.NeedModule,
 		Tests: false,
 	}
+	if p.order == OrderSource {
+		// positions of the declarations are read from the syntax.
+		cfg.Mode |= packages.NeedSyntax
+	}
+	pkg, err := p.load(cfg, patterns...)
+	if err != nil {
+		return err
+	}
+
+	p.ParsedPkg = &Package{
+		Name:    pkg.Name,
+		Pkg:     pkg.Types,
+		Files:   pkg.Syntax,
+		Fset:    pkg.Fset,
+		GoFiles: pkg.GoFiles,
+	}
+	return nil
+}
+
+// LoadPackageName returns the name and the import path of the package matching pattern.
+// The types are not loaded.
+func (p *Parser) LoadPackageName(pattern string) (name, path string, err error) {
+	pkg, err := p.load(&packages.Config{Mode: packages.NeedName}, pattern)
+	if err != nil {
+		return "", "", err
+	}
+	return pkg.Name, pkg.PkgPath, nil
+}
+
+// load loads the only one package matching patterns.
+func (p *Parser) load(cfg *packages.Config, patterns ...string) (*packages.Package, error) {
 	pkgs, err := packages.Load(cfg, patterns...)
 	if err != nil {
 		p.log.Println(err)
-		return err
+		return nil, err
 	}
 	err = p.PrintErrors(pkgs)
 	if err != nil {
 		p.log.Println(err)
-		return err
+		return nil, err
 	}
 
 	if len(pkgs) != 1 {
 		err = fmt.Errorf("error: %d packages found", len(pkgs))
 		p.log.Println(err.Error())
-		return err
+		return nil, err
 	}
-
-	pkg := pkgs[0]
-	p.ParsedPkg = &Package{
-		Name:  pkg.Name,
-		Pkg:   pkg.Types,
-		Files: pkg.Syntax,
-	}
-	return nil
+	return pkgs[0], nil
 }
 
 // PrintErrors prints to logger the accumulated errors of all
@@ -12,9 +12,16 @@ func (p *Parser) parseInterfaceObj(obj types.Object) (*model.Interface, error) {
 	if err != nil {
 		return nil, err
 	}
-	methods := []*model.Func{}
+	sels := make([]*types.Selection, 0, mset.Len())
 	for i := 0; i < mset.Len(); i++ {
-		method := mset.At(i)
+		sels = append(sels, mset.At(i))
+	}
+	if err := p.sortMethods(sels); err != nil {
+		return nil, err
+	}
+
+	methods := []*model.Func{}
+	for _, method := range sels {
 		mtype, err := p.parseType(method.Type())
 		if err != nil {
 			return nil, err
@@ -23,13 +30,13 @@ func (p *Parser) parseInterfaceObj(obj types.Object) (*model.Interface, error) {
 		if !ok {
 			return nil, fmt.Errorf("internal error")
 		}
-		methods = append(methods,
-			model.NewFunc(
-				method.Obj().Name(),
-				sig,
-				"",
-			),
+		m := model.NewFunc(
+			method.Obj().Name(),
+			sig,
+			"",
 		)
+		m.SetDoc(p.methodDoc(method.Obj()))
+		methods = append(methods, m)
 	}
 
 	// Check if this is a generic interface by examining the type
@@ -56,9 +63,40 @@ func (p *Parser) parseInterfaceObj(obj types.Object) (*model.Interface, error) {
 			methods,
 		)
 	}
+	intf.SetDoc(p.typeDoc(obj))
 	return intf, nil
 }
 
+// parseStructObj parses the named struct type and its fields.
+func (p *Parser) parseStructObj(obj types.Object) (*model.Struct, error) {
+	tp := p.newTypeParser()
+	typ, err := tp.parseType(obj.Type())
+	if err != nil {
+		return nil, err
+	}
+	named, ok := typ.(*model.TypeNamed)
+	if !ok {
+		return nil, fmt.Errorf("internal error. %s is %T", obj.Name(), typ)
+	}
+	st, ok := named.Org().(*model.TypeStruct)
+	if !ok {
+		return nil, fmt.Errorf("%s is not struct", obj.Name())
+	}
+
+	pkgInfo := model.NewPkgInfo(obj.Pkg().Name(), obj.Pkg().Path(), "")
+	var s *model.Struct
+	if named.IsGeneric() {
+		s = model.NewGenericStruct(obj.Name(), pkgInfo, named.TypeParams())
+	} else {
+		s = model.NewStruct(obj.Name(), pkgInfo)
+	}
+	for _, f := range st.Fields() {
+		s.AddField(f)
+	}
+	s.SetDoc(p.typeDoc(obj))
+	return s, nil
+}
+
 func (*Parser) getMethodSet(obj types.Object, pointer bool) (*types.MethodSet, error) {
 	t := obj.Type()
 	if pointer {
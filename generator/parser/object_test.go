@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
 )
 
 func TestParseInterfaceObj(t *testing.T) {
@@ -206,3 +208,54 @@ func TestParseInterfaceObjWithComplexMethods(t *testing.T) {
 		t.Error("parseInterfaceObj() missing Get method")
 	}
 }
+
+func TestParseStructObj(t *testing.T) {
+	parser := NewParser(
+		OptLogger(log.New(os.Stderr, "", log.LstdFlags|log.Lshortfile)),
+	)
+
+	// Create interface in other package: events.Bus
+	eventsPkg := types.NewPackage("example.com/events", "events")
+	publishSig := types.NewSignatureType(
+		nil, nil, nil,
+		types.NewTuple(types.NewParam(0, nil, "name", types.Typ[types.String])),
+		types.NewTuple(types.NewParam(0, nil, "", types.Universe.Lookup("error").Type())),
+		false,
+	)
+	busIntf := types.NewInterfaceType([]*types.Func{types.NewFunc(0, eventsPkg, "Publish", publishSig)}, nil)
+	busIntf.Complete()
+	bus := types.NewNamed(types.NewTypeName(0, eventsPkg, "Bus", nil), busIntf, nil)
+
+	// Create struct: type OrderService struct { bus events.Bus; name string }
+	pkg := types.NewPackage("example.com/app", "app")
+	st := types.NewStruct([]*types.Var{
+		types.NewField(0, pkg, "bus", bus, false),
+		types.NewField(0, pkg, "name", types.Typ[types.String], false),
+	}, nil)
+	obj := types.NewTypeName(0, pkg, "OrderService", nil)
+	types.NewNamed(obj, st, nil)
+
+	result, err := parser.parseStructObj(obj)
+	if err != nil {
+		t.Fatalf("parseStructObj() error = %v", err)
+	}
+
+	if result.Name() != "OrderService" {
+		t.Errorf("parseStructObj() Name() = %v, want %v", result.Name(), "OrderService")
+	}
+
+	if len(result.Fields()) != 2 {
+		t.Fatalf("parseStructObj() Fields() count = %v, want %v", len(result.Fields()), 2)
+	}
+
+	busField, ok := result.Fields()[0].Type().(*model.TypeNamed)
+	if !ok {
+		t.Fatalf("parseStructObj() field type = %T, want *model.TypeNamed", result.Fields()[0].Type())
+	}
+	if busField.Pkg().Path() != "example.com/events" {
+		t.Errorf("field package = %v, want %v", busField.Pkg().Path(), "example.com/events")
+	}
+	if _, ok := busField.Org().(*model.TypeInterface); !ok {
+		t.Errorf("field underlying type = %T, want *model.TypeInterface", busField.Org())
+	}
+}
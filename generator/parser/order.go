@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+)
+
+// MethodOrder is the order of the methods of parsed interfaces.
+type MethodOrder string
+
+// MethodOrders.
+const (
+	OrderName   MethodOrder = "name"   // alphabetical order
+	OrderSource MethodOrder = "source" // declaration order in the source
+)
+
+// ParseMethodOrder returns MethodOrder.
+func ParseMethodOrder(s string) (MethodOrder, error) {
+	switch o := MethodOrder(s); o {
+	case OrderName, OrderSource:
+		return o, nil
+	}
+	return "", fmt.Errorf("invalid order %q: must be one of %s, %s", s, OrderName, OrderSource)
+}
+
+// sortMethods sorts methods in p.order.
+// In OrderSource, the methods declared in the parsed package come first
+// in the order of files and positions, and the others follow by name.
+func (p *Parser) sortMethods(methods []*types.Selection) error {
+	byName := func(i, j int) bool {
+		return methods[i].Obj().Name() < methods[j].Obj().Name()
+	}
+
+	if p.order != OrderSource {
+		sort.SliceStable(methods, byName)
+		return nil
+	}
+
+	if p.ParsedPkg.Fset == nil || len(p.ParsedPkg.Files) == 0 {
+		return fmt.Errorf("source order requires the syntax of the package")
+	}
+	fileIdx := map[string]int{}
+	for i, f := range p.ParsedPkg.Files {
+		fileIdx[p.ParsedPkg.Fset.File(f.Pos()).Name()] = i
+	}
+	type key struct {
+		file   int
+		offset int
+	}
+	keys := map[*types.Selection]key{}
+	for _, m := range methods {
+		k := key{file: len(p.ParsedPkg.Files)}
+		if pos := m.Obj().Pos(); pos.IsValid() {
+			position := p.ParsedPkg.Fset.Position(pos)
+			if i, ok := fileIdx[position.Filename]; ok {
+				k = key{file: i, offset: position.Offset}
+			}
+		}
+		keys[m] = k
+	}
+
+	sort.SliceStable(methods, func(i, j int) bool {
+		ki, kj := keys[methods[i]], keys[methods[j]]
+		if ki.file != kj.file {
+			return ki.file < kj.file
+		}
+		if ki.offset != kj.offset {
+			return ki.offset < kj.offset
+		}
+		return byName(i, j)
+	})
+	return nil
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func newOrderTestPackage(t *testing.T) *Package {
+	return checkTestPackage(t,
+		`package test
+type Service struct{}
+func (Service) Stop() {}
+func (*Service) Start() {}
+`,
+		`package test
+func (s *Service) Restart() {}
+func (s Service) Health() bool { return true }
+`,
+	)
+}
+
+func TestParseMethodOrder(t *testing.T) {
+	tests := []struct {
+		s         string
+		expected  MethodOrder
+		expectErr bool
+	}{
+		{"name", OrderName, false},
+		{"source", OrderSource, false},
+		{"", "", true},
+		{"random", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseMethodOrder(tt.s)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ParseMethodOrder(%q) error = %v, expectErr %v", tt.s, err, tt.expectErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseMethodOrder(%q) = %v, want %v", tt.s, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseStructMethodOrder(t *testing.T) {
+	tests := []struct {
+		order    MethodOrder
+		expected string
+	}{
+		{OrderName, "Health,Restart,Start,Stop"},
+		{OrderSource, "Stop,Start,Restart,Health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			// repeat to detect the order depending on map iteration
+			for i := 0; i < 10; i++ {
+				p := NewParser(
+					OptPackage(newOrderTestPackage(t)),
+					OptParseTarget([]string{"Service"}),
+					OptMethodOrder(tt.order),
+				)
+				pkg, err := p.Parse()
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+				names := []string{}
+				for _, m := range pkg.Interfaces[0].Methods() {
+					names = append(names, m.Name())
+				}
+				if got := strings.Join(names, ","); got != tt.expected {
+					t.Fatalf("methods = %v, want %v", got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSourceOrderWithoutSyntax(t *testing.T) {
+	pkg := newOrderTestPackage(t)
+	pkg.Fset = nil
+	p := NewParser(
+		OptPackage(pkg),
+		OptParseTarget([]string{"Service"}),
+		OptMethodOrder(OrderSource),
+	)
+	if _, err := p.Parse(); err == nil {
+		t.Error("Parse() should return error when the syntax is not loaded")
+	}
+}
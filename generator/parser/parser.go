@@ -16,13 +16,19 @@ type Parser struct {
 	ParsedPkg   *Package
 	Targets     []string // if nil , all element is parsed.
 	stopLoadErr bool
+	order       MethodOrder
+	receiver    Receiver
+	docs        *docs            // loaded on demand
+	funcDocs    map[string]*docs // import path -> docs of the packages of the functions loaded by LoadFuncs
 	log         *log.Logger
 }
 
 // NewParser returns Parser.
 func NewParser(opts ...Opts) *Parser {
 	p := &Parser{
-		log: log.New(io.Discard, "", 0),
+		order:    OrderName,
+		receiver: ReceiverBoth,
+		log:      log.New(io.Discard, "", 0),
 	}
 	for _, opt := range opts {
 		opt(p)
@@ -37,7 +43,7 @@ func (p *Parser) Parse() (*model.Package, error) {
 		p.log.Println(err)
 		return nil, err
 	}
-	if len(p.Targets) != 1 {
+	if len(p.Targets) == 0 {
 		err := fmt.Errorf("unsupported parser settings")
 		p.log.Println(err)
 		return nil, err
@@ -95,6 +101,12 @@ func (p *Parser) setContents(pkg *model.Package, name string) error {
 		}
 		pkg.Interfaces = append(pkg.Interfaces, intf)
 
+		s, err := p.parseStructObj(obj)
+		if err != nil {
+			return err
+		}
+		pkg.Structs = append(pkg.Structs, s)
+
 	} else {
 		return fmt.Errorf("%s is unsupported", obj.Type())
 	}
@@ -113,70 +125,60 @@ func isStruct(t types.Type) bool {
 	return false
 }
 
+// methodSignature returns the signature of the method selection.
+// The type of the selection is the signature with the type arguments substituted,
+// which is available only if the method has the receiver.
+func methodSignature(sel *types.Selection) types.Type {
+	if sig, ok := sel.Obj().Type().(*types.Signature); ok && sig.Recv() == nil {
+		return sig
+	}
+	return sel.Type()
+}
+
 // parseStructAsInterface converts a struct to an interface by extracting its methods
 func (p *Parser) parseStructAsInterface(obj types.Object) (*model.Interface, error) {
 	structType := obj.Type()
-
-	// Get method set including both value and pointer receiver methods
-	methodSet := types.NewMethodSet(structType)
-	pointerMethodSet := types.NewMethodSet(types.NewPointer(structType))
-
-	// Combine both method sets
-	allMethods := make(map[string]*types.Func)
-
-	// Add value receiver methods
-	for i := 0; i < methodSet.Len(); i++ {
-		sel := methodSet.At(i)
-		if method, ok := sel.Obj().(*types.Func); ok && method.Exported() {
-			allMethods[method.Name()] = method
+	tp := p.newTypeParser()
+
+	// For generic struct, instantiate it with its own type parameters,
+	// so that the methods refer to them instead of the type parameters of each receiver.
+	var typeParams []*model.TypeParameter
+	if named, ok := structType.(*types.Named); ok && named.TypeParams().Len() > 0 {
+		var err error
+		typeParams, err = tp.parseTypeParameters(named.TypeParams())
+		if err != nil {
+			return nil, err
+		}
+		targs := make([]types.Type, named.TypeParams().Len())
+		for i := range targs {
+			targs[i] = named.TypeParams().At(i)
+		}
+		structType, err = types.Instantiate(nil, named, targs, false)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	// Add pointer receiver methods
-	for i := 0; i < pointerMethodSet.Len(); i++ {
-		sel := pointerMethodSet.At(i)
-		if method, ok := sel.Obj().(*types.Func); ok && method.Exported() {
-			allMethods[method.Name()] = method
-		}
+	// Get methods in the selected method set
+	sels := p.structMethods(structType)
+	if err := p.sortMethods(sels); err != nil {
+		return nil, err
 	}
 
-	// Convert to model.Func
+	// Convert to model.Func, parsing signatures in the same way as interfaces.
 	var modelMethods []*model.Func
-	for _, method := range allMethods {
-		sig := method.Type().(*types.Signature)
-
-		// Parse parameters
-		var params []*model.Parameter
-		if sig.Params() != nil {
-			for i := 0; i < sig.Params().Len(); i++ {
-				param := sig.Params().At(i)
-				paramType, err := p.parseType(param.Type())
-				if err != nil {
-					return nil, fmt.Errorf("failed to parse parameter type for method %s: %v", method.Name(), err)
-				}
-				params = append(params, model.NewParameter(param.Name(), paramType))
-			}
+	for _, sel := range sels {
+		typ, err := tp.parseType(methodSignature(sel))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse method %s: %v", sel.Obj().Name(), err)
 		}
-
-		// Parse return values
-		var returns []*model.Parameter
-		if sig.Results() != nil {
-			for i := 0; i < sig.Results().Len(); i++ {
-				result := sig.Results().At(i)
-				returnType, err := p.parseType(result.Type())
-				if err != nil {
-					return nil, fmt.Errorf("failed to parse return type for method %s: %v", method.Name(), err)
-				}
-				returns = append(returns, model.NewParameter(result.Name(), returnType))
-			}
+		sig, ok := typ.(*model.TypeSignature)
+		if !ok {
+			return nil, fmt.Errorf("internal error. %s is %T", sel.Obj().Name(), typ)
 		}
-
-		// Create type signature
-		typeSig := model.NewTypeSignature(params, nil, returns)
-
-		// Create model function
-		modelMethod := model.NewFunc(method.Name(), typeSig, "")
-		modelMethods = append(modelMethods, modelMethod)
+		method := model.NewFunc(sel.Obj().Name(), sig, "")
+		method.SetDoc(p.methodDoc(sel.Obj()))
+		modelMethods = append(modelMethods, method)
 	}
 
 	// Create interface name by appending "Interface" to struct name
@@ -186,7 +188,9 @@ func (p *Parser) parseStructAsInterface(obj types.Object) (*model.Interface, err
 	pkgInfo := model.NewPkgInfo(obj.Pkg().Name(), obj.Pkg().Path(), "")
 
 	// Create interface
-	intf := model.NewInterface(interfaceName, pkgInfo, modelMethods)
-
-	return intf, nil
+	// The doc of the struct describes the implementation, so it is not carried onto the interface.
+	if len(typeParams) > 0 {
+		return model.NewGenericInterface(interfaceName, pkgInfo, modelMethods, typeParams), nil
+	}
+	return model.NewInterface(interfaceName, pkgInfo, modelMethods), nil
 }
@@ -1,12 +1,38 @@
 package parser
 
 import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	goparser "go/parser"
+	"go/token"
 	"go/types"
 	"log"
 	"os"
 	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
 )
 
+// checkTestPackage type-checks srcs as files of the package "test".
+func checkTestPackage(t *testing.T, srcs ...string) *Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	files := []*ast.File{}
+	for i, src := range srcs {
+		f, err := goparser.ParseFile(fset, fmt.Sprintf("file%d.go", i), src, goparser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	pkg, err := (&types.Config{Importer: importer.Default()}).Check("test", fset, files, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Package{Name: "test", Files: files, Fset: fset, Pkg: pkg}
+}
+
 func TestNewParser(t *testing.T) {
 	// Test parser creation with default options
 	parser := NewParser()
@@ -120,15 +146,15 @@ func TestParserParseMethod(t *testing.T) {
 		t.Error("Parse() should return error when ParsedPkg is nil")
 	}
 
-	// Test with invalid target count
+	// Test with targets not found
 	parser.ParsedPkg = &Package{
 		Pkg: types.NewPackage("test", "test"),
 	}
-	parser.Targets = []string{"Interface1", "Interface2"} // More than 1
+	parser.Targets = []string{"Interface1", "Interface2"}
 
 	_, err = parser.Parse()
 	if err == nil {
-		t.Error("Parse() should return error when target count is not 1")
+		t.Error("Parse() should return error when targets are not found")
 	}
 
 	// Test with no targets
@@ -311,3 +337,109 @@ func TestStructParsing(t *testing.T) {
 		t.Error("Expected method 'SetName' not found")
 	}
 }
+
+// TestStructAsInterfaceRoundTrip tests that the source struct implements the extracted interface.
+func TestStructAsInterfaceRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		target   string
+		typeArgs string // type arguments to instantiate generic target
+	}{
+		{
+			name:   "variadic",
+			target: "Logger",
+			src: `package test
+type Logger struct{}
+func (l *Logger) Logf(format string, args ...any) {}
+func (l *Logger) Write(ps ...[]byte) (int, error) { return 0, nil }
+`,
+		},
+		{
+			name:   "named results",
+			target: "Divider",
+			src: `package test
+type Divider struct{}
+func (Divider) Divide(a, b int) (q, r int, err error) { return }
+func (Divider) Check(a int) (err error) { return }
+func (Divider) Apply(fn func(int) (n int)) {}
+`,
+		},
+		{
+			name:   "nested generic types",
+			target: "Store",
+			src: `package test
+type Pair[K comparable, V any] struct { Key K; Value V }
+type Store struct{}
+func (*Store) Entries(filter func(Pair[string, []int]) bool) map[string][]*Pair[string, []int] { return nil }
+func (*Store) Flags() []Pair[int, bool] { return nil }
+`,
+		},
+		{
+			name:   "imported and promoted",
+			target: "Client",
+			src: `package test
+import (
+	"strings"
+	"time"
+)
+type base struct{}
+func (base) Timeout() time.Duration { return 0 }
+type Client struct{ base }
+func (*Client) Build(b *strings.Builder, parts ...string) {}
+`,
+		},
+		{
+			name:     "generic",
+			target:   "Cache",
+			typeArgs: "[string, []byte]",
+			src: `package test
+type Cache[K comparable, V any] struct{ m map[K]V }
+func (c *Cache[K, V]) Get(k K) (V, bool) { v, ok := c.m[k]; return v, ok }
+func (c *Cache[A, B]) Put(k A, v B) {}
+func (c Cache[_, V]) Values(fn func(V)) {}
+`,
+		},
+		{
+			name:   "promoted from generic",
+			target: "Names",
+			src: `package test
+type list[T any] struct{ items []T }
+func (l *list[T]) Add(items ...T) {}
+func (l list[T]) At(i int) T { return l.items[i] }
+type Names struct{ *list[string] }
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := checkTestPackage(t, tt.src)
+			p := NewParser(OptPackage(pkg), OptParseTarget([]string{tt.target}))
+			parsed, err := p.Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			intf := parsed.Interfaces[0]
+
+			file := model.NewFile("", parsed.Name, parsed.Path, parsed.CopyDependencies())
+			file.AddInterface(intf)
+			file.DependenciesTidy()
+			generated := file.PrintCode()
+
+			assertion := fmt.Sprintf("package test\nvar _ %s%s = (*%s%s)(nil)\n", intf.Name(), tt.typeArgs, tt.target, tt.typeArgs)
+			fset := token.NewFileSet()
+			files := []*ast.File{}
+			for i, src := range []string{tt.src, generated, assertion} {
+				f, err := goparser.ParseFile(fset, fmt.Sprintf("file%d.go", i), src, 0)
+				if err != nil {
+					t.Fatalf("ParseFile() error = %v\n%s", err, src)
+				}
+				files = append(files, f)
+			}
+			if _, err := (&types.Config{Importer: importer.Default()}).Check("test", fset, files, nil); err != nil {
+				t.Errorf("%s does not implement %s: %v\n%s", tt.target, intf.Name(), err, generated)
+			}
+		})
+	}
+}
@@ -39,3 +39,17 @@ func OptStopLoadErr() Opts {
 		p.stopLoadErr = true
 	}
 }
+
+// OptMethodOrder set the order of methods. The default is OrderName.
+func OptMethodOrder(order MethodOrder) Opts {
+	return func(p *Parser) {
+		p.order = order
+	}
+}
+
+// OptReceiver set the method set of struct to parse as interface. The default is ReceiverBoth.
+func OptReceiver(receiver Receiver) Opts {
+	return func(p *Parser) {
+		p.receiver = receiver
+	}
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// Receiver selects the method set of struct to parse as interface.
+type Receiver string
+
+// Receivers.
+const (
+	ReceiverValue   Receiver = "value"   // method set of T
+	ReceiverPointer Receiver = "pointer" // methods of *T which T does not have
+	ReceiverBoth    Receiver = "both"    // method set of *T
+)
+
+// ParseReceiver returns Receiver.
+func ParseReceiver(s string) (Receiver, error) {
+	switch r := Receiver(s); r {
+	case ReceiverValue, ReceiverPointer, ReceiverBoth:
+		return r, nil
+	}
+	return "", fmt.Errorf("invalid receiver %q: must be one of %s, %s, %s", s, ReceiverValue, ReceiverPointer, ReceiverBoth)
+}
+
+// structMethods returns the exported methods of structType in the method set selected by p.receiver.
+func (p *Parser) structMethods(structType types.Type) []*types.Selection {
+	exported := func(mset *types.MethodSet) map[string]*types.Selection {
+		methods := map[string]*types.Selection{}
+		for i := 0; i < mset.Len(); i++ {
+			sel := mset.At(i)
+			if method, ok := sel.Obj().(*types.Func); ok && method.Exported() {
+				methods[method.Name()] = sel
+			}
+		}
+		return methods
+	}
+	valueMethods := exported(types.NewMethodSet(structType))
+	pointerMethods := exported(types.NewMethodSet(types.NewPointer(structType)))
+
+	sels := []*types.Selection{}
+	switch p.receiver {
+	case ReceiverValue:
+		for _, sel := range valueMethods {
+			sels = append(sels, sel)
+		}
+	case ReceiverPointer:
+		for name, sel := range pointerMethods {
+			if _, ok := valueMethods[name]; !ok {
+				sels = append(sels, sel)
+			}
+		}
+	default:
+		for _, sel := range pointerMethods {
+			sels = append(sels, sel)
+		}
+	}
+	return sels
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseReceiver(t *testing.T) {
+	tests := []struct {
+		s         string
+		expected  Receiver
+		expectErr bool
+	}{
+		{"value", ReceiverValue, false},
+		{"pointer", ReceiverPointer, false},
+		{"both", ReceiverBoth, false},
+		{"", "", true},
+		{"ref", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseReceiver(tt.s)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ParseReceiver(%q) error = %v, expectErr %v", tt.s, err, tt.expectErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseReceiver(%q) = %v, want %v", tt.s, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseStructReceiver(t *testing.T) {
+	src := `package test
+type Inner struct{}
+func (Inner) Describe() string { return "" }
+func (*Inner) Reset() {}
+type Money struct{ Inner; v int }
+func (m Money) Amount() int { return m.v }
+func (m *Money) Add(n int) { m.v += n }
+func (m Money) private() {}
+`
+	tests := []struct {
+		receiver Receiver
+		expected string
+	}{
+		{ReceiverValue, "Amount,Describe"},
+		{ReceiverPointer, "Add,Reset"},
+		{ReceiverBoth, "Add,Amount,Describe,Reset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.receiver), func(t *testing.T) {
+			p := NewParser(
+				OptPackage(checkTestPackage(t, src)),
+				OptParseTarget([]string{"Money"}),
+				OptReceiver(tt.receiver),
+			)
+			pkg, err := p.Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			names := []string{}
+			for _, m := range pkg.Interfaces[0].Methods() {
+				names = append(names, m.Name())
+			}
+			if got := strings.Join(names, ","); got != tt.expected {
+				t.Errorf("methods = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+// ParseWithResults parses the only one struct target, and the structs of the package
+// which its methods return as pointers, recursively, such as *sql.Tx and *sql.Rows of sql.DB.
+// Only the structs which have the exported methods are parsed.
+// The interfaces and the structs of the returned package are in the order they are found, the target first.
+func (p *Parser) ParseWithResults() (*model.Package, error) {
+	if p.ParsedPkg == nil || len(p.Targets) != 1 {
+		err := fmt.Errorf("invalid parser settings")
+		p.log.Println(err)
+		return nil, err
+	}
+
+	targets := []string{p.Targets[0]}
+	seen := map[string]bool{p.Targets[0]: true}
+	for i := 0; i < len(targets); i++ {
+		obj := p.ParsedPkg.Pkg.Scope().Lookup(targets[i])
+		if obj == nil {
+			return nil, fmt.Errorf("%s not found", targets[i])
+		}
+		if !isStruct(obj.Type()) {
+			return nil, fmt.Errorf("%s is not struct", targets[i])
+		}
+		if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+			return nil, fmt.Errorf("generic struct %s is unsupported", targets[i])
+		}
+
+		sels := p.structMethods(obj.Type())
+		sort.Slice(sels, func(i, j int) bool {
+			return sels[i].Obj().Name() < sels[j].Obj().Name()
+		})
+		for _, sel := range sels {
+			sig, ok := methodSignature(sel).(*types.Signature)
+			if !ok {
+				continue
+			}
+			for j := 0; j < sig.Results().Len(); j++ {
+				name, ok := p.structResult(sig.Results().At(j).Type())
+				if ok && !seen[name] {
+					seen[name] = true
+					targets = append(targets, name)
+				}
+			}
+		}
+	}
+
+	p.Targets = targets
+	return p.Parse()
+}
+
+// structResult returns the name of the struct of the parsed package which t points to,
+// if the struct has the exported methods.
+func (p *Parser) structResult(t types.Type) (string, bool) {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return "", false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return "", false
+	}
+	obj := named.Obj()
+	if obj.Pkg() != p.ParsedPkg.Pkg || !obj.Exported() || named.TypeParams().Len() > 0 || !isStruct(named) {
+		return "", false
+	}
+	if len(p.structMethods(named)) == 0 {
+		return "", false
+	}
+	return obj.Name(), true
+}
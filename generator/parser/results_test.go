@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWithResults(t *testing.T) {
+	pkg := checkTestPackage(t, `package test
+
+type DB struct{}
+
+func (*DB) Begin() (*Tx, error) { return nil, nil }
+func (*DB) Stats() *Stats        { return nil }
+func (*DB) Query() (*Rows, error) { return nil, nil }
+func (*DB) Close() error         { return nil }
+
+type Tx struct{}
+
+func (*Tx) Query() (*Rows, error) { return nil, nil }
+func (*Tx) Commit() error         { return nil }
+
+type Rows struct{}
+
+func (*Rows) Next() bool          { return false }
+func (*Rows) Tx() *Tx             { return nil }
+
+// no methods
+type Stats struct{ Open int }
+
+type Value struct{}
+
+func (Value) String() string { return "" }
+`)
+
+	tests := []struct {
+		name      string
+		target    string
+		expected  string
+		expectErr bool
+	}{
+		{name: "graph", target: "DB", expected: "DB,Tx,Rows"},
+		{name: "cycle", target: "Rows", expected: "Rows,Tx"},
+		{name: "no results", target: "Value", expected: "Value"},
+		{name: "not found", target: "Nope", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(OptPackage(pkg), OptParseTarget([]string{tt.target}))
+			got, err := p.ParseWithResults()
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ParseWithResults() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err != nil {
+				return
+			}
+			names := []string{}
+			for i, st := range got.Structs {
+				names = append(names, st.Name())
+				if got.Interfaces[i].Name() != st.Name()+"Interface" {
+					t.Errorf("Interfaces[%d] = %s, want %sInterface", i, got.Interfaces[i].Name(), st.Name())
+				}
+			}
+			if strings.Join(names, ",") != tt.expected {
+				t.Errorf("ParseWithResults() structs = %v, want %v", strings.Join(names, ","), tt.expected)
+			}
+		})
+	}
+}
@@ -37,6 +37,12 @@ func (tp *typeParser) getParsedKey(t types.Type) parsedKey {
 			path = tt.Obj().Pkg().Path()
 		}
 		key = path + "::" + tt.Obj().Name()
+		// each instance of generic type is the different type.
+		if args := tt.TypeArgs(); args != nil && args.Len() > 0 {
+			for i := 0; i < args.Len(); i++ {
+				key += "," + types.TypeString(args.At(i), nil)
+			}
+		}
 	}
 
 	return parsedKey(key)
@@ -107,6 +113,8 @@ func (tp *typeParser) parseType(t types.Type) (model.Type, error) {
 	}
 
 	switch tt := t.(type) {
+	case *types.Alias:
+		m, err = tp.parseType(types.Unalias(tt))
 	case *types.Array:
 		m, err = tp.parseArray(tt)
 	case *types.Slice:
@@ -224,6 +232,23 @@ func (tp *typeParser) parseNamedType(t *types.Named) (model.Type, error) {
 	if t.Obj().Pkg() != nil {
 		pkg = model.NewPkgInfo(t.Obj().Pkg().Name(), t.Obj().Pkg().Path(), "")
 	}
+	// Parse type arguments if this is an instantiated generic type
+	if args := t.TypeArgs(); args != nil && args.Len() > 0 {
+		org, err := tp.parseType(t.Underlying())
+		if err != nil {
+			return nil, err
+		}
+		typeArgs := []model.Type{}
+		for i := 0; i < args.Len(); i++ {
+			arg, err := tp.parseType(args.At(i))
+			if err != nil {
+				return nil, err
+			}
+			typeArgs = append(typeArgs, arg)
+		}
+		return model.NewInstantiatedTypeNamed(pkg, t.Obj().Name(), org, typeArgs), nil
+	}
+
 	org, err := tp.parseType(t.Obj().Type().Underlying())
 	if err != nil {
 		return nil, err
@@ -337,6 +362,7 @@ func (tp *typeParser) parseTypeParameters(typeParams *types.TypeParamList) ([]*m
 			return nil, err
 		}
 		typeParam := model.NewTypeParameter(param.Obj().Name(), constraint, i)
+		typeParam.SetComparable(types.Comparable(param))
 		params = append(params, typeParam)
 	}
 	return params, nil
@@ -392,5 +418,7 @@ func (tp *typeParser) parseTypeParam(param *types.TypeParam) (model.Type, error)
 	if err != nil {
 		return nil, err
 	}
-	return model.NewTypeParameter(param.Obj().Name(), constraint, param.Index()), nil
+	typeParam := model.NewTypeParameter(param.Obj().Name(), constraint, param.Index())
+	typeParam.SetComparable(types.Comparable(param))
+	return typeParam, nil
 }
@@ -323,3 +323,23 @@ func TestParseVariadicType(t *testing.T) {
 		t.Errorf("Expected TypeSignature, got %T", result)
 	}
 }
+
+func TestParseAliasType(t *testing.T) {
+	p := NewParser()
+	tp := p.newTypeParser()
+
+	// Create alias type: type Names = []string
+	pkg := types.NewPackage("example.com/test", "test")
+	aliasType := types.NewAlias(types.NewTypeName(token.NoPos, pkg, "Names", nil), types.NewSlice(types.Typ[types.String]))
+
+	result, err := tp.parseType(aliasType)
+	if err != nil {
+		t.Fatalf("parseType() error = %v", err)
+	}
+
+	typeStr := result.PrintType("", model.PackageMap{})
+	expected := "[]string"
+	if typeStr != expected {
+		t.Errorf("PrintType() = %v, want %v", typeStr, expected)
+	}
+}
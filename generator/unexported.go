@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+// UnexportedMode is how to handle methods referring to unexported types
+// which cannot be referred from the output package.
+type UnexportedMode string
+
+// UnexportedModes.
+const (
+	UnexportedWarn  UnexportedMode = "warn"  // report and generate them anyway
+	UnexportedSkip  UnexportedMode = "skip"  // report and skip the methods
+	UnexportedError UnexportedMode = "error" // fail
+)
+
+// ParseUnexportedMode returns UnexportedMode.
+func ParseUnexportedMode(s string) (UnexportedMode, error) {
+	switch m := UnexportedMode(s); m {
+	case UnexportedWarn, UnexportedSkip, UnexportedError:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid mode %q: must be one of %s, %s, %s", s, UnexportedWarn, UnexportedSkip, UnexportedError)
+}
+
+// FilterUnexported reports the references from the methods of intf to unexported types
+// which cannot be referred from the package of myPkgPath, and handles them according to mode.
+// In UnexportedSkip mode, returns the interface without the methods.
+func FilterUnexported(intf *model.Interface, myPkgPath string, mode UnexportedMode, logger *log.Logger) (*model.Interface, error) {
+	refs := model.UnexportedRefs(intf.Methods(), myPkgPath)
+	if len(refs) == 0 {
+		return intf, nil
+	}
+
+	switch mode {
+	case UnexportedError:
+		msgs := []string{}
+		for _, ref := range refs {
+			msgs = append(msgs, ref.String())
+		}
+		return nil, fmt.Errorf("%s refers to unexported types:\n\t%s", intf.Name(), strings.Join(msgs, "\n\t"))
+
+	case UnexportedSkip:
+		skip := map[string]bool{}
+		for _, ref := range refs {
+			logger.Printf("skip %s", ref)
+			skip[ref.Method] = true
+		}
+		methods := []*model.Func{}
+		for _, m := range intf.Methods() {
+			if !skip[m.Name()] {
+				methods = append(methods, m)
+			}
+		}
+		var filtered *model.Interface
+		if intf.IsGeneric() {
+			filtered = model.NewGenericInterface(intf.Name(), intf.Type().Pkg(), methods, intf.TypeParams())
+		} else {
+			filtered = model.NewInterface(intf.Name(), intf.Type().Pkg(), methods)
+		}
+		filtered.SetDoc(intf.Doc())
+		return filtered, nil
+
+	default:
+		for _, ref := range refs {
+			logger.Printf("warning: %s", ref)
+		}
+		return intf, nil
+	}
+}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/codegen/generator/model"
+)
+
+func TestParseUnexportedMode(t *testing.T) {
+	tests := []struct {
+		s         string
+		expected  UnexportedMode
+		expectErr bool
+	}{
+		{"warn", UnexportedWarn, false},
+		{"skip", UnexportedSkip, false},
+		{"error", UnexportedError, false},
+		{"", "", true},
+		{"ignore", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseUnexportedMode(tt.s)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ParseUnexportedMode(%q) error = %v, expectErr %v", tt.s, err, tt.expectErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseUnexportedMode(%q) = %v, want %v", tt.s, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterUnexported(t *testing.T) {
+	pkg := model.NewPkgInfo("other", "example.com/other", "")
+	hidden := model.NewTypeNamed(pkg, "key", model.NewTypeBasic("string"))
+	intf := model.NewInterface("Store", pkg, []*model.Func{
+		model.NewFunc("Get", model.NewTypeSignature([]*model.Parameter{model.NewParameter("k", hidden)}, nil, nil), ""),
+		model.NewFunc("Len", model.NewTypeSignature(nil, nil, []*model.Parameter{model.NewParameter("", model.NewTypeBasic("int"))}), ""),
+	})
+
+	tests := []struct {
+		mode      UnexportedMode
+		methods   []string
+		expectErr bool
+		logged    string
+	}{
+		{UnexportedWarn, []string{"Get", "Len"}, false, "warning: Get: k: unexported type example.com/other.key"},
+		{UnexportedSkip, []string{"Len"}, false, "skip Get: k: unexported type example.com/other.key"},
+		{UnexportedError, nil, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			got, err := FilterUnexported(intf, "example.com/mine", tt.mode, log.New(buf, "", 0))
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("FilterUnexported() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), "Get: k") {
+					t.Errorf("FilterUnexported() error = %v, should contain the path", err)
+				}
+				return
+			}
+			names := []string{}
+			for _, m := range got.Methods() {
+				names = append(names, m.Name())
+			}
+			if strings.Join(names, ",") != strings.Join(tt.methods, ",") {
+				t.Errorf("FilterUnexported() methods = %v, want %v", names, tt.methods)
+			}
+			if !strings.Contains(buf.String(), tt.logged) {
+				t.Errorf("FilterUnexported() logged %q, want %q", buf.String(), tt.logged)
+			}
+		})
+	}
+
+	got, err := FilterUnexported(intf, "example.com/other", UnexportedError, log.New(&bytes.Buffer{}, "", 0))
+	if err != nil || got != intf {
+		t.Errorf("FilterUnexported() in the same package = %v, %v, want the interface as is", got, err)
+	}
+}
+
+func TestFilterUnexportedExternalTest(t *testing.T) {
+	pkg := model.NewPkgInfo("calc", "example.com/calc", "")
+	key := model.NewTypeNamed(pkg, "key", model.NewTypeBasic("string"))
+	entry := model.NewTypeNamed(pkg, "entry", model.NewTypeStruct(nil))
+	value := model.NewTypeNamed(model.NewPkgInfo("other", "example.com/other", ""), "Value", model.NewTypeBasic("int"))
+	intf := model.NewInterface("Cache", pkg, []*model.Func{
+		model.NewFunc("Get", model.NewTypeSignature(
+			[]*model.Parameter{model.NewParameter("k", key)},
+			nil,
+			[]*model.Parameter{model.NewParameter("", model.NewTypeMap(key, model.NewPointer(entry)))},
+		), ""),
+		model.NewFunc("Other", model.NewTypeSignature(nil, nil, []*model.Parameter{model.NewParameter("", value)}), ""),
+	})
+
+	// the external test package of calc cannot refer to the unexported types of calc.
+	_, err := FilterUnexported(intf, "example.com/calc_test", UnexportedError, log.New(&bytes.Buffer{}, "", 0))
+	if err == nil {
+		t.Fatal("FilterUnexported() should return error for unexported types")
+	}
+	for _, want := range []string{"Get: k: unexported type example.com/calc.key", "result0[value]: unexported type example.com/calc.entry"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("FilterUnexported() error = %v, want to contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "Other") {
+		t.Errorf("FilterUnexported() error = %v, should not name exported types", err)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/kmio11/codegen/cmd/decorate"
+	"github.com/kmio11/codegen/cmd/harness"
 	ifacecommand "github.com/kmio11/codegen/cmd/interface"
+	"github.com/kmio11/codegen/cmd/middleware"
 	"github.com/kmio11/codegen/cmd/mock"
 )
 
@@ -20,6 +23,9 @@ var (
 	commands = []Command{
 		mock.New(),
 		ifacecommand.New(),
+		harness.New(),
+		decorate.New(),
+		middleware.New(),
 	}
 )
 